@@ -2,5 +2,9 @@ module tender
 
 go 1.23.8
 
-require github.com/go-chi/chi/v5 v5.2.1
+require (
+	github.com/go-chi/chi/v5 v5.2.1
+	github.com/google/uuid v1.6.0
+)
 
+require github.com/lib/pq v1.10.9
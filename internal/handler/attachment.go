@@ -0,0 +1,286 @@
+package handler
+
+import (
+    "bufio"
+    "errors"
+    "io"
+    "log"
+    "mime"
+    "net/http"
+    "path/filepath"
+    "strconv"
+
+    "github.com/go-chi/chi/v5"
+
+    "tender/internal/model"
+    "tender/internal/service"
+    "tender/internal/validate"
+)
+
+// AttachmentHandler serves the documents attached to tenders, under
+// /api/tenders/{id}/attachments, and to bids, under
+// /api/bids/{id}/attachments. Uploads are multipart/form-data with the
+// document in a part named "file".
+type AttachmentHandler struct {
+    svc *service.AttachmentService
+}
+
+func NewAttachmentHandler(s *service.AttachmentService) *AttachmentHandler {
+    return &AttachmentHandler{svc: s}
+}
+
+func (h *AttachmentHandler) TenderRoutes() chi.Router {
+    r := chi.NewRouter()
+    r.Get("/", h.listTender)
+    r.Post("/", h.attachTender)
+    r.Get("/{attachmentId}", h.downloadTender)
+    r.Delete("/{attachmentId}", h.detachTender)
+    return r
+}
+
+func (h *AttachmentHandler) BidRoutes() chi.Router {
+    r := chi.NewRouter()
+    r.Get("/", h.listBid)
+    r.Post("/", h.attachBid)
+    r.Get("/{attachmentId}", h.downloadBid)
+    r.Delete("/{attachmentId}", h.detachBid)
+    return r
+}
+
+func (h *AttachmentHandler) listTender(w http.ResponseWriter, r *http.Request) {
+    id := chi.URLParam(r, "id")
+    username := r.URL.Query().Get("username")
+    page, err := parsePage(r)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    v := &validate.Validator{}
+    checkID(v, "tenderId", id)
+    checkUsername(v, "username", username, false)
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    res, next, err := h.svc.TenderAttachments(id, username, page)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writePage(w, res, next)
+}
+
+func (h *AttachmentHandler) attachTender(w http.ResponseWriter, r *http.Request) {
+    id := chi.URLParam(r, "id")
+    username := r.URL.Query().Get("username")
+    v := &validate.Validator{}
+    checkID(v, "tenderId", id)
+    checkUsername(v, "username", username, true)
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    u, err := readUpload(r)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    tender, a, err := h.svc.WithRequestID(requestID(r)).AttachToTender(id, username, ifMatch(r), u)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writeVersioned(w, tender.Version, a)
+}
+
+func (h *AttachmentHandler) downloadTender(w http.ResponseWriter, r *http.Request) {
+    id, attachmentID := chi.URLParam(r, "id"), chi.URLParam(r, "attachmentId")
+    q := r.URL.Query()
+    v := &validate.Validator{}
+    checkID(v, "tenderId", id)
+    checkID(v, "attachmentId", attachmentID)
+    checkUsername(v, "username", q.Get("username"), false)
+    ver := checkAttachmentVersion(v, q.Get("version"))
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    a, content, err := h.svc.OpenTenderAttachment(id, attachmentID, q.Get("username"), ver)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writeAttachment(w, a, content)
+}
+
+func (h *AttachmentHandler) detachTender(w http.ResponseWriter, r *http.Request) {
+    id, attachmentID := chi.URLParam(r, "id"), chi.URLParam(r, "attachmentId")
+    username := r.URL.Query().Get("username")
+    v := &validate.Validator{}
+    checkID(v, "tenderId", id)
+    checkID(v, "attachmentId", attachmentID)
+    checkUsername(v, "username", username, true)
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    tender, a, err := h.svc.WithRequestID(requestID(r)).DetachFromTender(id, attachmentID, username, ifMatch(r))
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writeVersioned(w, tender.Version, a)
+}
+
+func (h *AttachmentHandler) listBid(w http.ResponseWriter, r *http.Request) {
+    id := chi.URLParam(r, "id")
+    username := r.URL.Query().Get("username")
+    page, err := parsePage(r)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    v := &validate.Validator{}
+    checkID(v, "bidId", id)
+    checkUsername(v, "username", username, false)
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    res, next, err := h.svc.BidAttachments(id, username, page)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writePage(w, res, next)
+}
+
+func (h *AttachmentHandler) attachBid(w http.ResponseWriter, r *http.Request) {
+    id := chi.URLParam(r, "id")
+    username := r.URL.Query().Get("username")
+    v := &validate.Validator{}
+    checkID(v, "bidId", id)
+    checkUsername(v, "username", username, false)
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    u, err := readUpload(r)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    bid, a, err := h.svc.WithRequestID(requestID(r)).AttachToBid(id, username, ifMatch(r), u)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writeVersioned(w, bid.Version, a)
+}
+
+func (h *AttachmentHandler) downloadBid(w http.ResponseWriter, r *http.Request) {
+    id, attachmentID := chi.URLParam(r, "id"), chi.URLParam(r, "attachmentId")
+    q := r.URL.Query()
+    v := &validate.Validator{}
+    checkID(v, "bidId", id)
+    checkID(v, "attachmentId", attachmentID)
+    checkUsername(v, "username", q.Get("username"), false)
+    ver := checkAttachmentVersion(v, q.Get("version"))
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    a, content, err := h.svc.OpenBidAttachment(id, attachmentID, ver, q.Get("username"))
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writeAttachment(w, a, content)
+}
+
+func (h *AttachmentHandler) detachBid(w http.ResponseWriter, r *http.Request) {
+    id, attachmentID := chi.URLParam(r, "id"), chi.URLParam(r, "attachmentId")
+    username := r.URL.Query().Get("username")
+    v := &validate.Validator{}
+    checkID(v, "bidId", id)
+    checkID(v, "attachmentId", attachmentID)
+    checkUsername(v, "username", username, false)
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    bid, a, err := h.svc.WithRequestID(requestID(r)).DetachFromBid(id, attachmentID, username, ifMatch(r))
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writeVersioned(w, bid.Version, a)
+}
+
+// checkAttachmentVersion parses the optional version an attachment is
+// downloaded as of; 0 stands for the current one.
+func checkAttachmentVersion(v *validate.Validator, raw string) int {
+    if raw == "" {
+        return 0
+    }
+    return checkVersion(v, "version", raw)
+}
+
+// readUpload finds the "file" part of a multipart/form-data request and
+// returns it as an upload streaming from the request body. A part without
+// a content type gets the one sniffed from its first bytes.
+func readUpload(r *http.Request) (service.Upload, error) {
+    mr, err := r.MultipartReader()
+    if err != nil {
+        return service.Upload{}, validate.Errors{{Field: "body", Reason: "must be multipart/form-data"}}
+    }
+    for {
+        part, err := mr.NextPart()
+        if errors.Is(err, io.EOF) {
+            return service.Upload{}, validate.Errors{{Field: "file", Reason: "is required"}}
+        }
+        if err != nil {
+            return service.Upload{}, validate.Errors{{Field: "body", Reason: err.Error()}}
+        }
+        if part.FormName() != "file" {
+            continue
+        }
+        v := &validate.Validator{}
+        name := filepath.Base(part.FileName())
+        if part.FileName() == "" || name == "." || name == string(filepath.Separator) {
+            v.Add("file", "must have a file name")
+        } else {
+            v.MaxLen("file", name, maxNameLen)
+        }
+        body := bufio.NewReader(part)
+        contentType := part.Header.Get("Content-Type")
+        if contentType == "" {
+            head, _ := body.Peek(512)
+            contentType = http.DetectContentType(head)
+        }
+        if _, _, err := mime.ParseMediaType(contentType); err != nil {
+            v.Add("file", "has an invalid content type")
+        }
+        if err := v.Err(); err != nil {
+            return service.Upload{}, err
+        }
+        return service.Upload{Name: name, ContentType: contentType, Body: body}, nil
+    }
+}
+
+// writeAttachment streams the content of a to the client as a download.
+func writeAttachment(w http.ResponseWriter, a model.Attachment, content io.ReadCloser) {
+    defer content.Close()
+    w.Header().Set("Content-Type", a.ContentType)
+    w.Header().Set("Content-Length", strconv.FormatInt(a.Size, 10))
+    disposition := mime.FormatMediaType("attachment", map[string]string{"filename": a.Name})
+    if disposition == "" {
+        disposition = "attachment"
+    }
+    w.Header().Set("Content-Disposition", disposition)
+    w.Header().Set("X-Content-Type-Options", "nosniff")
+    w.WriteHeader(http.StatusOK)
+    if _, err := io.Copy(w, content); err != nil {
+        log.Printf("download attachment %s: %v", a.ID, err)
+    }
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+    "net/http"
+    "time"
+
+    "github.com/go-chi/chi/v5"
+
+    "tender/internal/service"
+    "tender/internal/validate"
+)
+
+// AuditHandler serves the audit log under /api/audit.
+type AuditHandler struct {
+    svc *service.AuditService
+}
+
+func NewAuditHandler(s *service.AuditService) *AuditHandler {
+    return &AuditHandler{svc: s}
+}
+
+func (h *AuditHandler) Routes() chi.Router {
+    r := chi.NewRouter()
+    r.Get("/", h.list)
+    r.Get("/verify", h.verify)
+    return r
+}
+
+func (h *AuditHandler) list(w http.ResponseWriter, r *http.Request) {
+    q := r.URL.Query()
+    username := q.Get("username")
+    page, err := parsePage(r)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    v := &validate.Validator{}
+    checkUsername(v, "username", username, true)
+    query := service.AuditQuery{
+        Actor:      q.Get("actor"),
+        Action:     q.Get("action"),
+        EntityType: q.Get("entityType"),
+        EntityID:   q.Get("entityId"),
+        TenderID:   q.Get("tenderId"),
+        Since:      checkTime(v, "since", q.Get("since")),
+        Until:      checkTime(v, "until", q.Get("until")),
+    }
+    checkUsername(v, "actor", query.Actor, false)
+    if query.Action != "" {
+        v.OneOf("action", query.Action, auditActions...)
+    }
+    if query.EntityType != "" {
+        v.OneOf("entityType", query.EntityType, auditEntities...)
+    }
+    if query.EntityID != "" {
+        v.UUID("entityId", query.EntityID)
+    }
+    if query.TenderID != "" {
+        v.UUID("tenderId", query.TenderID)
+    }
+    if !query.Since.IsZero() && !query.Until.IsZero() && !query.Since.Before(query.Until) {
+        v.Add("since", "must be before until")
+    }
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    res, next, err := h.svc.List(username, query, page)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writePage(w, res, next)
+}
+
+func (h *AuditHandler) verify(w http.ResponseWriter, r *http.Request) {
+    username := r.URL.Query().Get("username")
+    v := &validate.Validator{}
+    checkUsername(v, "username", username, true)
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    res, err := h.svc.Verify(username)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writeJSON(w, http.StatusOK, res)
+}
+
+// checkTime parses an optional RFC 3339 time query parameter.
+func checkTime(v *validate.Validator, field, raw string) time.Time {
+    if raw == "" {
+        return time.Time{}
+    }
+    t, err := time.Parse(time.RFC3339, raw)
+    if err != nil {
+        v.Add(field, "must be an RFC 3339 time")
+    }
+    return t
+}
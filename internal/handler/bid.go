@@ -1,13 +1,14 @@
 package handler
 
 import (
-    "encoding/json"
+    "fmt"
     "net/http"
-    "strconv"
 
     "github.com/go-chi/chi/v5"
 
+    "tender/internal/model"
     "tender/internal/service"
+    "tender/internal/validate"
 )
 
 type BidHandler struct {
@@ -27,168 +28,514 @@ func (h *BidHandler) Routes() chi.Router {
         r.Put("/status", h.status)
         r.Patch("/edit", h.edit)
         r.Put("/submit_decision", h.decision)
+        r.Put("/evaluation", h.evaluate)
         r.Put("/feedback", h.feedback)
+        r.Put("/price", h.price)
         r.Put("/rollback/{version}", h.rollback)
+        r.Get("/versions", h.versions)
+        r.Get("/versions/{version}", h.version)
+        r.Get("/diff", h.diff)
     })
     r.Get("/{tenderId}/list", h.listTender)
     r.Get("/{tenderId}/reviews", h.reviews)
+    r.Get("/{tenderId}/leaderboard", h.leaderboard)
+    r.Get("/{tenderId}/ranking", h.ranking)
     return r
 }
 
+type createBidRequest struct {
+    Name        string `json:"name"`
+    Description string `json:"description"`
+    TenderID    string `json:"tenderId"`
+    AuthorType  string `json:"authorType"`
+    AuthorID    string `json:"authorId"`
+    // Price is optional except on auctions.
+    Price *model.Money `json:"price"`
+    // LotIDs are required on tenders with lots.
+    LotIDs []string `json:"lotIds"`
+}
+
+func (req createBidRequest) validate(v *validate.Validator) {
+    if v.Required("name", req.Name) {
+        v.MaxLen("name", req.Name, maxNameLen)
+    }
+    if v.Required("description", req.Description) {
+        v.MaxLen("description", req.Description, maxDescriptionLen)
+    }
+    checkID(v, "tenderId", req.TenderID)
+    if v.Required("authorType", req.AuthorType) {
+        v.OneOf("authorType", req.AuthorType, authorTypes...)
+    }
+    checkID(v, "authorId", req.AuthorID)
+    if req.Price != nil {
+        checkMoney(v, "price", *req.Price)
+    }
+    for i, id := range req.LotIDs {
+        checkID(v, fmt.Sprintf("lotIds[%d]", i), id)
+    }
+}
+
+// editBidRequest holds optional fields; nil means unchanged.
+type editBidRequest struct {
+    Name        *string `json:"name"`
+    Description *string `json:"description"`
+}
+
+func (req editBidRequest) validate(v *validate.Validator) {
+    if req.Name != nil && v.Required("name", *req.Name) {
+        v.MaxLen("name", *req.Name, maxNameLen)
+    }
+    if req.Description != nil && v.Required("description", *req.Description) {
+        v.MaxLen("description", *req.Description, maxDescriptionLen)
+    }
+}
+
 func (h *BidHandler) create(w http.ResponseWriter, r *http.Request) {
     if r.Method != http.MethodPost {
-        http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+        writeReason(w, http.StatusMethodNotAllowed, "method not allowed")
         return
     }
-    var req struct {
-        Name        string `json:"name"`
-        Description string `json:"description"`
-        TenderID    string `json:"tenderId"`
-        AuthorType  string `json:"authorType"`
-        AuthorID    string `json:"authorId"`
+    var req createBidRequest
+    if err := decodeJSON(r, &req); err != nil {
+        writeError(w, err)
+        return
     }
-    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-        http.Error(w, "bad request", http.StatusBadRequest)
+    v := &validate.Validator{}
+    req.validate(v)
+    if err := v.Err(); err != nil {
+        writeError(w, err)
         return
     }
-    b, err := h.svc.Create(req.Name, req.Description, req.TenderID, req.AuthorType, req.AuthorID)
+    b, err := h.svc.WithRequestID(requestID(r)).Create(req.Name, req.Description, req.TenderID, req.AuthorType, req.AuthorID, req.Price,
+        req.LotIDs)
     if err != nil {
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+        writeError(w, err)
         return
     }
-    writeJSON(w, http.StatusOK, b)
+    writeVersioned(w, b.Version, b)
 }
 
 func (h *BidHandler) userBids(w http.ResponseWriter, r *http.Request) {
     if r.Method != http.MethodGet {
-        http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+        writeReason(w, http.StatusMethodNotAllowed, "method not allowed")
         return
     }
-    res := h.svc.UserBids(r.URL.Query().Get("username"))
-    writeJSON(w, http.StatusOK, res)
+    page, err := parsePage(r)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    username := r.URL.Query().Get("username")
+    v := &validate.Validator{}
+    checkUsername(v, "username", username, true)
+    order := checkBidSort(v, r.URL.Query())
+    history := checkInclude(v, r.URL.Query())
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    res, next, err := h.svc.UserBids(username, order, page)
+    if err == nil && history {
+        res, err = h.svc.WithHistory(res...)
+    }
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writePage(w, res, next)
 }
 
 func (h *BidHandler) listTender(w http.ResponseWriter, r *http.Request) {
     if r.Method != http.MethodGet {
-        http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+        writeReason(w, http.StatusMethodNotAllowed, "method not allowed")
         return
     }
     tenderID := chi.URLParam(r, "tenderId")
-    res := h.svc.ListForTender(tenderID)
-    writeJSON(w, http.StatusOK, res)
+    page, err := parsePage(r)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    v := &validate.Validator{}
+    checkID(v, "tenderId", tenderID)
+    lotID := r.URL.Query().Get("lotId")
+    if lotID != "" {
+        v.UUID("lotId", lotID)
+    }
+    order := checkBidSort(v, r.URL.Query())
+    history := checkInclude(v, r.URL.Query())
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    res, next, err := h.svc.ListForTender(tenderID, lotID, order, page)
+    if err == nil && history {
+        res, err = h.svc.WithHistory(res...)
+    }
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writePage(w, res, next)
 }
 
 func (h *BidHandler) status(w http.ResponseWriter, r *http.Request) {
     id := chi.URLParam(r, "id")
+    q := r.URL.Query()
+    status := q.Get("status")
+    v := &validate.Validator{}
+    checkID(v, "bidId", id)
+    checkUsername(v, "username", q.Get("username"), false)
+    if r.Method == http.MethodPut && v.Required("status", status) {
+        v.OneOf("status", status, bidStatuses...)
+    }
+    history := checkInclude(v, q)
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
     switch r.Method {
     case http.MethodGet:
-        for _, b := range h.svc.ListForTender("") {
-            if b.ID == id {
-                writeJSON(w, http.StatusOK, map[string]string{"status": b.Status})
-                return
-            }
+        bid, err := h.svc.Get(id)
+        if err != nil {
+            writeError(w, err)
+            return
         }
-        http.NotFound(w, r)
+        writeVersioned(w, bid.Version, map[string]string{"status": bid.Status})
     case http.MethodPut:
-        status := r.URL.Query().Get("status")
-        if status == "" {
-            http.Error(w, "missing status", http.StatusBadRequest)
-            return
+        bid, err := h.svc.WithRequestID(requestID(r)).UpdateStatus(id, status, q.Get("username"), ifMatch(r))
+        if err == nil && history {
+            bid, err = h.withHistory(bid)
         }
-        bid, err := h.svc.UpdateStatus(id, status)
         if err != nil {
-            http.Error(w, err.Error(), http.StatusInternalServerError)
+            writeError(w, err)
             return
         }
-        writeJSON(w, http.StatusOK, bid)
+        writeVersioned(w, bid.Version, bid)
     default:
-        http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+        writeReason(w, http.StatusMethodNotAllowed, "method not allowed")
     }
 }
 
 func (h *BidHandler) edit(w http.ResponseWriter, r *http.Request) {
     if r.Method != http.MethodPatch {
-        http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+        writeReason(w, http.StatusMethodNotAllowed, "method not allowed")
         return
     }
     id := chi.URLParam(r, "id")
-    var req struct {
-        Name        *string `json:"name"`
-        Description *string `json:"description"`
+    username := r.URL.Query().Get("username")
+    var req editBidRequest
+    if err := decodeJSON(r, &req); err != nil {
+        writeError(w, err)
+        return
     }
-    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-        http.Error(w, "bad request", http.StatusBadRequest)
+    v := &validate.Validator{}
+    checkID(v, "bidId", id)
+    checkUsername(v, "username", username, false)
+    req.validate(v)
+    history := checkInclude(v, r.URL.Query())
+    if err := v.Err(); err != nil {
+        writeError(w, err)
         return
     }
-    bid, err := h.svc.Edit(id, req.Name, req.Description)
+    bid, err := h.svc.WithRequestID(requestID(r)).Edit(id, username, ifMatch(r), req.Name, req.Description)
+    if err == nil && history {
+        bid, err = h.withHistory(bid)
+    }
     if err != nil {
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+        writeError(w, err)
         return
     }
-    writeJSON(w, http.StatusOK, bid)
+    writeVersioned(w, bid.Version, bid)
 }
 
 func (h *BidHandler) decision(w http.ResponseWriter, r *http.Request) {
     if r.Method != http.MethodPut {
-        http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+        writeReason(w, http.StatusMethodNotAllowed, "method not allowed")
+        return
+    }
+    id := chi.URLParam(r, "id")
+    q := r.URL.Query()
+    v := &validate.Validator{}
+    checkID(v, "bidId", id)
+    if v.Required("decision", q.Get("decision")) {
+        v.OneOf("decision", q.Get("decision"), bidDecisions...)
+    }
+    checkUsername(v, "username", q.Get("username"), true)
+    history := checkInclude(v, q)
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    bid, err := h.svc.WithRequestID(requestID(r)).Decision(id, q.Get("decision"), q.Get("username"))
+    if err == nil && history {
+        bid, err = h.withHistory(bid)
+    }
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writeVersioned(w, bid.Version, bid)
+}
+
+// evaluateBidRequest holds an evaluator's scores by criterion name.
+type evaluateBidRequest struct {
+    Scores map[string]int `json:"scores"`
+}
+
+// evaluate records the caller's scores for a bid.
+func (h *BidHandler) evaluate(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodPut {
+        writeReason(w, http.StatusMethodNotAllowed, "method not allowed")
+        return
+    }
+    id := chi.URLParam(r, "id")
+    username := r.URL.Query().Get("username")
+    var req evaluateBidRequest
+    if err := decodeJSON(r, &req); err != nil {
+        writeError(w, err)
+        return
+    }
+    v := &validate.Validator{}
+    checkID(v, "bidId", id)
+    checkUsername(v, "username", username, true)
+    if len(req.Scores) == 0 {
+        v.Add("scores", "is required")
+    }
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    e, err := h.svc.WithRequestID(requestID(r)).Evaluate(id, username, req.Scores)
+    if err != nil {
+        writeError(w, err)
         return
     }
+    writeJSON(w, http.StatusOK, e)
+}
+
+// ranking lists the bids on a tender by weighted score.
+func (h *BidHandler) ranking(w http.ResponseWriter, r *http.Request) {
+    tenderID := chi.URLParam(r, "tenderId")
+    page, err := parsePage(r)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    username := r.URL.Query().Get("username")
+    v := &validate.Validator{}
+    checkID(v, "tenderId", tenderID)
+    checkUsername(v, "username", username, true)
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    res, next, err := h.svc.Ranking(tenderID, username, page)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writePage(w, res, next)
+}
+
+// price lowers the price of a bid in an auction.
+func (h *BidHandler) price(w http.ResponseWriter, r *http.Request) {
     id := chi.URLParam(r, "id")
-    dec := r.URL.Query().Get("decision")
-    if dec == "" {
-        http.Error(w, "missing decision", http.StatusBadRequest)
+    q := r.URL.Query()
+    v := &validate.Validator{}
+    checkID(v, "bidId", id)
+    price := parsePrice(v, "price", q.Get("price"), q.Get("currency"))
+    checkUsername(v, "username", q.Get("username"), false)
+    history := checkInclude(v, q)
+    if err := v.Err(); err != nil {
+        writeError(w, err)
         return
     }
-    bid, err := h.svc.Decision(id, dec)
+    bid, err := h.svc.WithRequestID(requestID(r)).LowerPrice(id, price, q.Get("username"), ifMatch(r))
+    if err == nil && history {
+        bid, err = h.withHistory(bid)
+    }
     if err != nil {
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+        writeError(w, err)
         return
     }
-    writeJSON(w, http.StatusOK, bid)
+    writeVersioned(w, bid.Version, bid)
+}
+
+// leaderboard lists the standings of the auction on a tender.
+func (h *BidHandler) leaderboard(w http.ResponseWriter, r *http.Request) {
+    tenderID := chi.URLParam(r, "tenderId")
+    page, err := parsePage(r)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    username := r.URL.Query().Get("username")
+    v := &validate.Validator{}
+    checkID(v, "tenderId", tenderID)
+    checkUsername(v, "username", username, false)
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    res, next, err := h.svc.Leaderboard(tenderID, username, page)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writePage(w, res, next)
 }
 
 func (h *BidHandler) feedback(w http.ResponseWriter, r *http.Request) {
     if r.Method != http.MethodPut {
-        http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+        writeReason(w, http.StatusMethodNotAllowed, "method not allowed")
         return
     }
     id := chi.URLParam(r, "id")
-    fb := r.URL.Query().Get("bidFeedback")
-    if fb == "" {
-        http.Error(w, "missing bidFeedback", http.StatusBadRequest)
+    q := r.URL.Query()
+    v := &validate.Validator{}
+    checkID(v, "bidId", id)
+    if v.Required("bidFeedback", q.Get("bidFeedback")) {
+        v.MaxLen("bidFeedback", q.Get("bidFeedback"), maxFeedbackLen)
+    }
+    checkUsername(v, "username", q.Get("username"), true)
+    history := checkInclude(v, q)
+    if err := v.Err(); err != nil {
+        writeError(w, err)
         return
     }
-    bid, err := h.svc.Feedback(id, fb)
+    bid, err := h.svc.WithRequestID(requestID(r)).Feedback(id, q.Get("bidFeedback"), q.Get("username"))
+    if err == nil && history {
+        bid, err = h.withHistory(bid)
+    }
     if err != nil {
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+        writeError(w, err)
         return
     }
-    writeJSON(w, http.StatusOK, bid)
+    writeVersioned(w, bid.Version, bid)
 }
 
 func (h *BidHandler) rollback(w http.ResponseWriter, r *http.Request) {
     if r.Method != http.MethodPut {
-        http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+        writeReason(w, http.StatusMethodNotAllowed, "method not allowed")
         return
     }
     id := chi.URLParam(r, "id")
-    ver, _ := strconv.Atoi(chi.URLParam(r, "version"))
-    bid, err := h.svc.Rollback(id, ver)
+    username := r.URL.Query().Get("username")
+    v := &validate.Validator{}
+    checkID(v, "bidId", id)
+    checkUsername(v, "username", username, false)
+    ver := checkVersion(v, "version", chi.URLParam(r, "version"))
+    history := checkInclude(v, r.URL.Query())
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    bid, err := h.svc.WithRequestID(requestID(r)).Rollback(id, ver, username, ifMatch(r))
+    if err == nil && history {
+        bid, err = h.withHistory(bid)
+    }
     if err != nil {
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+        writeError(w, err)
         return
     }
-    writeJSON(w, http.StatusOK, bid)
+    writeVersioned(w, bid.Version, bid)
 }
 
 func (h *BidHandler) reviews(w http.ResponseWriter, r *http.Request) {
     if r.Method != http.MethodGet {
-        http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+        writeReason(w, http.StatusMethodNotAllowed, "method not allowed")
         return
     }
     tenderID := chi.URLParam(r, "tenderId")
-    author := r.URL.Query().Get("authorUsername")
-    res := h.svc.Reviews(tenderID, author)
+    page, err := parsePage(r)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    q := r.URL.Query()
+    v := &validate.Validator{}
+    checkID(v, "tenderId", tenderID)
+    checkUsername(v, "authorUsername", q.Get("authorUsername"), true)
+    checkUsername(v, "requesterUsername", q.Get("requesterUsername"), true)
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    res, next, err := h.svc.Reviews(tenderID, q.Get("authorUsername"), q.Get("requesterUsername"), page)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writePage(w, res, next)
+}
+
+func (h *BidHandler) versions(w http.ResponseWriter, r *http.Request) {
+    id := chi.URLParam(r, "id")
+    username := r.URL.Query().Get("username")
+    page, err := parsePage(r)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    v := &validate.Validator{}
+    checkID(v, "bidId", id)
+    checkUsername(v, "username", username, false)
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    res, next, err := h.svc.Versions(id, username, page)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writePage(w, res, next)
+}
+
+func (h *BidHandler) version(w http.ResponseWriter, r *http.Request) {
+    id := chi.URLParam(r, "id")
+    username := r.URL.Query().Get("username")
+    v := &validate.Validator{}
+    checkID(v, "bidId", id)
+    checkUsername(v, "username", username, false)
+    ver := checkVersion(v, "version", chi.URLParam(r, "version"))
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    res, err := h.svc.Version(id, ver, username)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
     writeJSON(w, http.StatusOK, res)
 }
 
+func (h *BidHandler) diff(w http.ResponseWriter, r *http.Request) {
+    id := chi.URLParam(r, "id")
+    q := r.URL.Query()
+    v := &validate.Validator{}
+    checkID(v, "bidId", id)
+    checkUsername(v, "username", q.Get("username"), false)
+    from, to := checkVersionRange(v, q)
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    res, err := h.svc.Diff(id, from, to, q.Get("username"))
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writeJSON(w, http.StatusOK, res)
+}
+
+// withHistory fills in the version history of b for ?include=history.
+func (h *BidHandler) withHistory(b model.Bid) (model.Bid, error) {
+    res, err := h.svc.WithHistory(b)
+    if err != nil {
+        return model.Bid{}, err
+    }
+    return res[0], nil
+}
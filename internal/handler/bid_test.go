@@ -0,0 +1,211 @@
+package handler
+
+import (
+    "bytes"
+    "encoding/json"
+    "io"
+    "mime/multipart"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/go-chi/chi/v5"
+
+    "tender/internal/model"
+    "tender/internal/service"
+    "tender/internal/storage"
+    "tender/internal/storage/blob"
+)
+
+const (
+    orgA   = "11111111-1111-1111-1111-111111111111"
+    orgB   = "22222222-2222-2222-2222-222222222222"
+    daveID = "00000004-0000-0000-0000-000000000000"
+)
+
+// secret marks every part of the sealed bid that must not leak.
+const secret = "SECRET"
+
+// newTestServer serves the tender, bid and attachment routes over a fresh
+// file store in which alice is responsible for orgA, dave for orgB and erin
+// for nothing.
+func newTestServer(t *testing.T) (*httptest.Server, *service.TenderService, *service.BidService, *service.AttachmentService) {
+    t.Helper()
+    dir := t.TempDir()
+    data, err := json.Marshal(storage.Data{
+        Employees: []model.Employee{
+            {ID: "00000001-0000-0000-0000-000000000000", Username: "alice"},
+            {ID: daveID, Username: "dave"},
+            {ID: "00000005-0000-0000-0000-000000000000", Username: "erin"},
+        },
+        Organizations: []model.Organization{{ID: orgA, Name: "A"}, {ID: orgB, Name: "B"}},
+        Responsibles: []model.OrganizationResponsible{
+            {ID: "r1", OrganizationID: orgA, UserID: "00000001-0000-0000-0000-000000000000"},
+            {ID: "r2", OrganizationID: orgB, UserID: daveID},
+        },
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+    path := filepath.Join(dir, "data.json")
+    if err := os.WriteFile(path, data, 0o644); err != nil {
+        t.Fatal(err)
+    }
+    repo, err := storage.New(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() { repo.Close() })
+    blobs, err := blob.NewFS(filepath.Join(dir, "blobs"))
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    tenders := service.NewTenderService(repo, repo, repo)
+    bids := service.NewBidService(repo, repo, repo, repo, repo, repo, repo)
+    attachments := service.NewAttachmentService(repo, repo, repo, blobs, repo)
+    r := chi.NewRouter()
+    r.Mount("/api/tenders", NewTenderHandler(tenders).Routes())
+    r.Mount("/api/bids", NewBidHandler(bids).Routes())
+    r.Mount("/api/bids/{id}/attachments", NewAttachmentHandler(attachments).BidRoutes())
+    srv := httptest.NewServer(r)
+    t.Cleanup(srv.Close)
+    return srv, tenders, bids, attachments
+}
+
+// do sends a request and returns the status and body of the response.
+func do(t *testing.T, method, url, contentType string, body []byte) (int, string) {
+    t.Helper()
+    req, err := http.NewRequest(method, url, bytes.NewReader(body))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if contentType != "" {
+        req.Header.Set("Content-Type", contentType)
+    }
+    resp, err := http.DefaultClient.Do(req)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer resp.Body.Close()
+    b, err := io.ReadAll(resp.Body)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return resp.StatusCode, string(b)
+}
+
+// upload returns a multipart/form-data body holding a file part.
+func upload(t *testing.T, name, content string) (string, []byte) {
+    t.Helper()
+    var buf bytes.Buffer
+    w := multipart.NewWriter(&buf)
+    part, err := w.CreateFormFile("file", name)
+    if err != nil {
+        t.Fatal(err)
+    }
+    part.Write([]byte(content))
+    if err := w.Close(); err != nil {
+        t.Fatal(err)
+    }
+    return w.FormDataContentType(), buf.Bytes()
+}
+
+// TestSealedBidDoesNotLeak calls every endpoint that returns a bid, or part
+// of one, as everyone but its author while the bids are sealed, and checks
+// that no response gives its name, description, price or attachments away.
+func TestSealedBidDoesNotLeak(t *testing.T) {
+    srv, tenders, bids, attachments := newTestServer(t)
+    deadline := time.Now().Add(time.Hour)
+    tender, err := tenders.Create("Sealed", "d", "Delivery", orgA, "alice", nil, service.Deadlines{Submission: &deadline}, true, nil, nil, nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, err := tenders.UpdateStatus(tender.ID, model.TenderPublished, "alice", service.AnyVersion); err != nil {
+        t.Fatal(err)
+    }
+    price := model.Money{Amount: 777, Currency: "RUB"}
+    bid, err := bids.Create(secret+"-name", secret+"-description", tender.ID, model.AuthorUser, daveID, &price, nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, err := bids.UpdateStatus(bid.ID, model.BidPublished, "dave", service.AnyVersion); err != nil {
+        t.Fatal(err)
+    }
+    _, a, err := attachments.AttachToBid(bid.ID, "dave", service.AnyVersion, service.Upload{
+        Name: secret + "-file.txt",
+        Body: strings.NewReader(secret + "-content"),
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    base := srv.URL + "/api/bids/"
+    for _, user := range []string{"", "alice", "erin"} {
+        q := "username=" + user + "&include=history"
+        contentType, form := upload(t, "other.txt", "other")
+        requests := []struct {
+            method, path, contentType string
+            body                      []byte
+        }{
+            {"GET", "my?" + q, "", nil},
+            {"GET", tender.ID + "/list?" + q, "", nil},
+            {"GET", tender.ID + "/list?sort=price&" + q, "", nil},
+            {"GET", tender.ID + "/reviews?authorUsername=dave&requesterUsername=" + user, "", nil},
+            {"GET", tender.ID + "/leaderboard?" + q, "", nil},
+            {"GET", tender.ID + "/ranking?" + q, "", nil},
+            {"GET", bid.ID + "/status?" + q, "", nil},
+            {"PUT", bid.ID + "/status?status=Published&" + q, "", nil},
+            {"PUT", bid.ID + "/status?status=Canceled&" + q, "", nil},
+            {"PATCH", bid.ID + "/edit?" + q, "application/json", []byte(`{"name": "renamed"}`)},
+            {"PUT", bid.ID + "/submit_decision?decision=Approved&" + q, "", nil},
+            {"PUT", bid.ID + "/submit_decision?decision=Rejected&" + q, "", nil},
+            {"PUT", bid.ID + "/evaluation?" + q, "application/json", []byte(`{"scores": {}}`)},
+            {"PUT", bid.ID + "/feedback?bidFeedback=fine&" + q, "", nil},
+            {"PUT", bid.ID + "/price?price=1&" + q, "", nil},
+            {"PUT", bid.ID + "/rollback/1?" + q, "", nil},
+            {"GET", bid.ID + "/versions?" + q, "", nil},
+            {"GET", bid.ID + "/versions/1?" + q, "", nil},
+            {"GET", bid.ID + "/versions/3?" + q, "", nil},
+            {"GET", bid.ID + "/diff?from=1&to=3&" + q, "", nil},
+            {"GET", bid.ID + "/attachments?" + q, "", nil},
+            {"GET", bid.ID + "/attachments/" + a.ID + "?" + q, "", nil},
+            {"GET", bid.ID + "/attachments/" + a.ID + "?version=3&" + q, "", nil},
+            {"POST", bid.ID + "/attachments?" + q, contentType, form},
+            {"DELETE", bid.ID + "/attachments/" + a.ID + "?" + q, "", nil},
+        }
+        for _, r := range requests {
+            code, body := do(t, r.method, base+r.path, r.contentType, r.body)
+            if strings.Contains(body, secret) {
+                t.Errorf("%s %s as %q: %d leaks the bid: %s", r.method, r.path, user, code, body)
+            }
+            if r.method != "GET" && code < 300 {
+                t.Errorf("%s %s as %q: %d, want a failure", r.method, r.path, user, code)
+            }
+        }
+    }
+
+    // The author sees their own bid, which shows the checks above can tell.
+    code, body := do(t, "GET", base+"my?username=dave", "", nil)
+    if code != http.StatusOK || !strings.Contains(body, secret) {
+        t.Fatalf("GET my as dave: %d %s", code, body)
+    }
+    code, body = do(t, "PATCH", base+bid.ID+"/edit?username=dave&include=history", "application/json", []byte(`{"description": "`+secret+`-new"}`))
+    if code != http.StatusOK || !strings.Contains(body, secret+"-name") {
+        t.Fatalf("PATCH edit as dave: %d %s", code, body)
+    }
+
+    // Opening the tender reveals the bid to everyone.
+    code, body = do(t, "PUT", srv.URL+"/api/tenders/"+tender.ID+"/open?username=alice", "", nil)
+    if code != http.StatusOK {
+        t.Fatalf("PUT open: %d %s", code, body)
+    }
+    code, body = do(t, "GET", base+tender.ID+"/list", "", nil)
+    if code != http.StatusOK || !strings.Contains(body, secret) {
+        t.Fatalf("GET list after opening: %d %s", code, body)
+    }
+}
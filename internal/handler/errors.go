@@ -0,0 +1,98 @@
+package handler
+
+import (
+    "errors"
+    "log"
+    "net/http"
+
+    "tender/internal/service"
+    "tender/internal/validate"
+)
+
+// errorResponse is the ErrorResponse schema of openapi.yml. Fields lists
+// the individual problems of a rejected request.
+type errorResponse struct {
+    Reason string                `json:"reason"`
+    Fields []validate.FieldError `json:"fields,omitempty"`
+}
+
+// writeReason writes a JSON error body with the given status code.
+func writeReason(w http.ResponseWriter, code int, reason string) {
+    writeJSON(w, code, errorResponse{Reason: reason})
+}
+
+// writeError translates a service error into its HTTP status and JSON body.
+// Unknown errors are logged and reported as 500 without leaking details.
+func writeError(w http.ResponseWriter, err error) {
+    var invalid validate.Errors
+    if errors.As(err, &invalid) {
+        writeJSON(w, http.StatusBadRequest, errorResponse{Reason: err.Error(), Fields: invalid})
+        return
+    }
+    code := statusFor(err)
+    if code == http.StatusInternalServerError {
+        log.Printf("internal error: %v", err)
+        writeReason(w, code, "internal server error")
+        return
+    }
+    writeReason(w, code, err.Error())
+}
+
+func statusFor(err error) int {
+    var (
+        transition *service.TransitionError
+        state      *service.StateError
+        deadline   *service.DeadlineError
+        undercut   *service.UndercutError
+        budget     *service.BudgetError
+    )
+    switch {
+    case errors.Is(err, service.ErrUnauthorized):
+        return http.StatusUnauthorized
+    case errors.Is(err, service.ErrForbidden),
+        errors.Is(err, service.ErrSealed):
+        return http.StatusForbidden
+    case errors.Is(err, service.ErrNotFound):
+        return http.StatusNotFound
+    case errors.Is(err, service.ErrPreconditionFailed):
+        return http.StatusPreconditionFailed
+    case errors.Is(err, service.ErrConflict):
+        return http.StatusConflict
+    case errors.Is(err, service.ErrAttachmentTooLarge):
+        return http.StatusRequestEntityTooLarge
+    case errors.Is(err, service.ErrInvalidStatus),
+        errors.Is(err, service.ErrInvalidDecision),
+        errors.Is(err, service.ErrDuplicateVote),
+        errors.Is(err, service.ErrInvalidPage),
+        errors.Is(err, service.ErrInvalidDeadline),
+        errors.Is(err, service.ErrNotSealed),
+        errors.Is(err, service.ErrInvalidAuction),
+        errors.Is(err, service.ErrNotAuction),
+        errors.Is(err, service.ErrAuctionNotStarted),
+        errors.Is(err, service.ErrPriceRequired),
+        errors.Is(err, service.ErrCurrencyMismatch),
+        errors.Is(err, service.ErrInvalidCriteria),
+        errors.Is(err, service.ErrNoCriteria),
+        errors.Is(err, service.ErrInvalidScores),
+        errors.Is(err, service.ErrInvalidLots),
+        errors.Is(err, service.ErrHasLots),
+        errors.Is(err, service.ErrTooManyAttachments),
+        errors.As(err, &undercut),
+        errors.As(err, &budget),
+        errors.As(err, &transition),
+        errors.As(err, &state),
+        errors.As(err, &deadline):
+        return http.StatusBadRequest
+    }
+    return http.StatusInternalServerError
+}
+
+// NotFound and MethodNotAllowed replace the router's plain-text defaults so
+// that every error response carries an ErrorResponse body.
+func NotFound(w http.ResponseWriter, r *http.Request) {
+    writeReason(w, http.StatusNotFound, "route not found")
+}
+
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+    writeReason(w, http.StatusMethodNotAllowed, "method not allowed")
+}
@@ -0,0 +1,193 @@
+package handler
+
+import (
+    "net/http"
+
+    "github.com/go-chi/chi/v5"
+
+    "tender/internal/model"
+    "tender/internal/service"
+    "tender/internal/validate"
+)
+
+// LotHandler serves the lots of a tender under /api/tenders/{id}/lots.
+// Lots are versioned with their tender: the ETag of a lot response is the
+// tender's version, and If-Match on a lot write expects it.
+type LotHandler struct {
+    svc *service.LotService
+}
+
+func NewLotHandler(s *service.LotService) *LotHandler {
+    return &LotHandler{svc: s}
+}
+
+func (h *LotHandler) Routes() chi.Router {
+    r := chi.NewRouter()
+    r.Get("/", h.list)
+    r.Post("/new", h.create)
+    r.Route("/{lotId}", func(r chi.Router) {
+        r.Get("/", h.get)
+        r.Get("/status", h.status)
+        r.Put("/status", h.status)
+        r.Put("/award", h.award)
+    })
+    return r
+}
+
+// lotRequest describes a new lot.
+type lotRequest struct {
+    Name        string       `json:"name"`
+    Description string       `json:"description"`
+    Quantity    int          `json:"quantity"`
+    Budget      *model.Money `json:"budget"`
+}
+
+// validate checks the lot; prefix names it in field errors.
+func (req lotRequest) validate(v *validate.Validator, prefix string) {
+    if v.Required(prefix+"name", req.Name) {
+        v.MaxLen(prefix+"name", req.Name, maxNameLen)
+    }
+    if v.Required(prefix+"description", req.Description) {
+        v.MaxLen(prefix+"description", req.Description, maxDescriptionLen)
+    }
+    v.Positive(prefix+"quantity", req.Quantity)
+    if req.Budget != nil {
+        checkMoney(v, prefix+"budget", *req.Budget)
+    }
+}
+
+func (req lotRequest) lot() model.Lot {
+    return model.Lot{Name: req.Name, Description: req.Description, Quantity: req.Quantity, Budget: req.Budget}
+}
+
+// toLots converts validated lots.
+func toLots(reqs []lotRequest) []model.Lot {
+    var res []model.Lot
+    for _, l := range reqs {
+        res = append(res, l.lot())
+    }
+    return res
+}
+
+func (h *LotHandler) list(w http.ResponseWriter, r *http.Request) {
+    tenderID := chi.URLParam(r, "id")
+    page, err := parsePage(r)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    q := r.URL.Query()
+    v := &validate.Validator{}
+    checkID(v, "tenderId", tenderID)
+    checkUsername(v, "username", q.Get("username"), false)
+    checkEnumList(v, "status", q["status"], lotStatuses)
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    res, next, err := h.svc.List(tenderID, q.Get("username"), q["status"], page)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writePage(w, res, next)
+}
+
+func (h *LotHandler) create(w http.ResponseWriter, r *http.Request) {
+    tenderID := chi.URLParam(r, "id")
+    username := r.URL.Query().Get("username")
+    var req lotRequest
+    if err := decodeJSON(r, &req); err != nil {
+        writeError(w, err)
+        return
+    }
+    v := &validate.Validator{}
+    checkID(v, "tenderId", tenderID)
+    checkUsername(v, "username", username, true)
+    req.validate(v, "")
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    t, lot, err := h.svc.WithRequestID(requestID(r)).Add(tenderID, username, ifMatch(r), req.lot())
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writeVersioned(w, t.Version, lot)
+}
+
+func (h *LotHandler) get(w http.ResponseWriter, r *http.Request) {
+    tenderID, lotID := chi.URLParam(r, "id"), chi.URLParam(r, "lotId")
+    username := r.URL.Query().Get("username")
+    v := &validate.Validator{}
+    checkID(v, "tenderId", tenderID)
+    checkID(v, "lotId", lotID)
+    checkUsername(v, "username", username, false)
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    t, lot, err := h.svc.Get(tenderID, lotID, username)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writeVersioned(w, t.Version, lot)
+}
+
+func (h *LotHandler) status(w http.ResponseWriter, r *http.Request) {
+    tenderID, lotID := chi.URLParam(r, "id"), chi.URLParam(r, "lotId")
+    q := r.URL.Query()
+    status := q.Get("status")
+    v := &validate.Validator{}
+    checkID(v, "tenderId", tenderID)
+    checkID(v, "lotId", lotID)
+    checkUsername(v, "username", q.Get("username"), r.Method == http.MethodPut)
+    if r.Method == http.MethodPut && v.Required("status", status) {
+        v.OneOf("status", status, lotStatuses...)
+    }
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    switch r.Method {
+    case http.MethodGet:
+        t, lot, err := h.svc.Get(tenderID, lotID, q.Get("username"))
+        if err != nil {
+            writeError(w, err)
+            return
+        }
+        writeVersioned(w, t.Version, map[string]string{"status": lot.Status})
+    case http.MethodPut:
+        t, lot, err := h.svc.WithRequestID(requestID(r)).UpdateStatus(tenderID, lotID, status, q.Get("username"), ifMatch(r))
+        if err != nil {
+            writeError(w, err)
+            return
+        }
+        writeVersioned(w, t.Version, lot)
+    default:
+        writeReason(w, http.StatusMethodNotAllowed, "method not allowed")
+    }
+}
+
+// award awards a lot to the bid given as bidId.
+func (h *LotHandler) award(w http.ResponseWriter, r *http.Request) {
+    tenderID, lotID := chi.URLParam(r, "id"), chi.URLParam(r, "lotId")
+    q := r.URL.Query()
+    v := &validate.Validator{}
+    checkID(v, "tenderId", tenderID)
+    checkID(v, "lotId", lotID)
+    checkID(v, "bidId", q.Get("bidId"))
+    checkUsername(v, "username", q.Get("username"), true)
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    t, lot, err := h.svc.WithRequestID(requestID(r)).Award(tenderID, lotID, q.Get("bidId"), q.Get("username"), ifMatch(r))
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writeVersioned(w, t.Version, lot)
+}
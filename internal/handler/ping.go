@@ -4,7 +4,7 @@ import "net/http"
 
 func Ping(w http.ResponseWriter, r *http.Request) {
     if r.Method != http.MethodGet {
-        http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+        writeReason(w, http.StatusMethodNotAllowed, "method not allowed")
         return
     }
     w.WriteHeader(http.StatusOK)
@@ -0,0 +1,137 @@
+package handler
+
+import (
+    "net/http"
+    "time"
+
+    "github.com/go-chi/chi/v5"
+
+    "tender/internal/service"
+    "tender/internal/validate"
+)
+
+// QuestionHandler serves the clarification board of a tender under
+// /api/tenders/{id}/questions.
+type QuestionHandler struct {
+    svc *service.QuestionService
+}
+
+func NewQuestionHandler(s *service.QuestionService) *QuestionHandler {
+    return &QuestionHandler{svc: s}
+}
+
+func (h *QuestionHandler) Routes() chi.Router {
+    r := chi.NewRouter()
+    r.Get("/", h.list)
+    r.Post("/new", h.ask)
+    r.Get("/{questionId}", h.get)
+    r.Put("/{questionId}/answer", h.answer)
+    return r
+}
+
+type askRequest struct {
+    Text string `json:"text"`
+}
+
+// answerRequest answers a question. SubmissionDeadline, when set, pushes
+// the submission deadline of the tender.
+type answerRequest struct {
+    Answer             string     `json:"answer"`
+    Publish            bool       `json:"publish"`
+    SubmissionDeadline *time.Time `json:"submissionDeadline"`
+}
+
+func (h *QuestionHandler) list(w http.ResponseWriter, r *http.Request) {
+    tenderID := chi.URLParam(r, "id")
+    username := r.URL.Query().Get("username")
+    page, err := parsePage(r)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    v := &validate.Validator{}
+    checkID(v, "tenderId", tenderID)
+    checkUsername(v, "username", username, false)
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    res, next, err := h.svc.List(tenderID, username, page)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writePage(w, res, next)
+}
+
+func (h *QuestionHandler) ask(w http.ResponseWriter, r *http.Request) {
+    tenderID := chi.URLParam(r, "id")
+    username := r.URL.Query().Get("username")
+    var req askRequest
+    if err := decodeJSON(r, &req); err != nil {
+        writeError(w, err)
+        return
+    }
+    v := &validate.Validator{}
+    checkID(v, "tenderId", tenderID)
+    checkUsername(v, "username", username, true)
+    if v.Required("text", req.Text) {
+        v.MaxLen("text", req.Text, maxDescriptionLen)
+    }
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    q, err := h.svc.WithRequestID(requestID(r)).Ask(tenderID, username, req.Text)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writeJSON(w, http.StatusOK, q)
+}
+
+func (h *QuestionHandler) get(w http.ResponseWriter, r *http.Request) {
+    tenderID, questionID := chi.URLParam(r, "id"), chi.URLParam(r, "questionId")
+    username := r.URL.Query().Get("username")
+    v := &validate.Validator{}
+    checkID(v, "tenderId", tenderID)
+    checkID(v, "questionId", questionID)
+    checkUsername(v, "username", username, false)
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    q, err := h.svc.Get(tenderID, questionID, username)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writeJSON(w, http.StatusOK, q)
+}
+
+func (h *QuestionHandler) answer(w http.ResponseWriter, r *http.Request) {
+    tenderID, questionID := chi.URLParam(r, "id"), chi.URLParam(r, "questionId")
+    username := r.URL.Query().Get("username")
+    var req answerRequest
+    if err := decodeJSON(r, &req); err != nil {
+        writeError(w, err)
+        return
+    }
+    v := &validate.Validator{}
+    checkID(v, "tenderId", tenderID)
+    checkID(v, "questionId", questionID)
+    checkUsername(v, "username", username, true)
+    if v.Required("answer", req.Answer) {
+        v.MaxLen("answer", req.Answer, maxDescriptionLen)
+    }
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    q, err := h.svc.WithRequestID(requestID(r)).Answer(tenderID, questionID, username, req.Answer, req.Publish, req.SubmissionDeadline)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writeJSON(w, http.StatusOK, q)
+}
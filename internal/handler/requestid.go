@@ -0,0 +1,49 @@
+package handler
+
+import (
+    "context"
+    "net/http"
+
+    "github.com/google/uuid"
+)
+
+// RequestIDHeader carries the ID of a request, both ways.
+const RequestIDHeader = "X-Request-Id"
+
+// maxRequestID bounds the length of a request ID taken from the client.
+const maxRequestID = 100
+
+type requestIDKey struct{}
+
+// RequestID gives every request an ID: the one in the X-Request-Id header
+// when it is printable ASCII of at most maxRequestID bytes, a new UUID
+// otherwise. The ID is echoed in the response and recorded in the audit log
+// with the changes the request makes.
+func RequestID(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        id := r.Header.Get(RequestIDHeader)
+        if !validRequestID(id) {
+            id = uuid.New().String()
+        }
+        w.Header().Set(RequestIDHeader, id)
+        next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+    })
+}
+
+// requestID returns the ID RequestID gave r, or "" outside of it.
+func requestID(r *http.Request) string {
+    id, _ := r.Context().Value(requestIDKey{}).(string)
+    return id
+}
+
+func validRequestID(id string) bool {
+    if id == "" || len(id) > maxRequestID {
+        return false
+    }
+    for i := 0; i < len(id); i++ {
+        if id[i] < 0x21 || id[i] > 0x7e {
+            return false
+        }
+    }
+    return true
+}
@@ -1,13 +1,16 @@
 package handler
 
 import (
-    "encoding/json"
+    "cmp"
+    "fmt"
     "net/http"
-    "strconv"
+    "time"
 
     "github.com/go-chi/chi/v5"
 
+    "tender/internal/model"
     "tender/internal/service"
+    "tender/internal/validate"
 )
 
 type TenderHandler struct {
@@ -28,117 +31,422 @@ func (h *TenderHandler) Routes() chi.Router {
         r.Put("/status", h.status)
         r.Patch("/edit", h.edit)
         r.Put("/rollback/{version}", h.rollback)
+        r.Put("/open", h.open)
+        r.Get("/versions", h.versions)
+        r.Get("/versions/{version}", h.version)
+        r.Get("/diff", h.diff)
     })
     return r
 }
 
+type createTenderRequest struct {
+    Name               string             `json:"name"`
+    Description        string             `json:"description"`
+    ServiceType        string             `json:"serviceType"`
+    OrganizationID     string             `json:"organizationId"`
+    CreatorUsername    string             `json:"creatorUsername"`
+    Budget             *model.Money       `json:"budget"`
+    SubmissionDeadline *time.Time         `json:"submissionDeadline"`
+    DecisionDeadline   *time.Time         `json:"decisionDeadline"`
+    Sealed             bool               `json:"sealed"`
+    Auction            *auctionRequest    `json:"auction"`
+    Criteria           []criterionRequest `json:"criteria"`
+    Lots               []lotRequest       `json:"lots"`
+}
+
+// auctionRequest holds the settings of a reverse auction; startsAt may be
+// left out to start right away.
+type auctionRequest struct {
+    StartsAt         time.Time   `json:"startsAt"`
+    EndsAt           time.Time   `json:"endsAt"`
+    Step             model.Money `json:"step"`
+    ExtensionSeconds int         `json:"extensionSeconds"`
+}
+
+func (req auctionRequest) validate(v *validate.Validator) {
+    if req.EndsAt.IsZero() {
+        v.Add("auction.endsAt", "is required")
+    }
+    checkMoney(v, "auction.step", req.Step)
+    if req.ExtensionSeconds < 0 {
+        v.Add("auction.extensionSeconds", "must not be negative")
+    }
+}
+
+// criterionRequest is an evaluation criterion; kind defaults to Score.
+type criterionRequest struct {
+    Name   string `json:"name"`
+    Kind   string `json:"kind"`
+    Weight int    `json:"weight"`
+}
+
+// checkCriteria validates the criteria of a tender.
+func checkCriteria(v *validate.Validator, criteria []criterionRequest) {
+    if len(criteria) > maxCriteria {
+        v.Add("criteria", fmt.Sprintf("must have at most %d items", maxCriteria))
+    }
+    for i, c := range criteria {
+        field := fmt.Sprintf("criteria[%d].", i)
+        if v.Required(field+"name", c.Name) {
+            v.MaxLen(field+"name", c.Name, maxNameLen)
+        }
+        if c.Kind != "" {
+            v.OneOf(field+"kind", c.Kind, criterionKinds...)
+        }
+        if c.Weight < 1 || c.Weight > maxWeight {
+            v.Add(field+"weight", fmt.Sprintf("must be from 1 to %d", maxWeight))
+        }
+    }
+}
+
+// toCriteria converts validated criteria, keeping nil and empty apart.
+func toCriteria(reqs []criterionRequest) []model.Criterion {
+    if reqs == nil {
+        return nil
+    }
+    res := make([]model.Criterion, len(reqs))
+    for i, c := range reqs {
+        res[i] = model.Criterion{Name: c.Name, Kind: cmp.Or(c.Kind, model.CriterionScore), Weight: c.Weight}
+    }
+    return res
+}
+
+func (req createTenderRequest) validate(v *validate.Validator) {
+    if v.Required("name", req.Name) {
+        v.MaxLen("name", req.Name, maxNameLen)
+    }
+    if v.Required("description", req.Description) {
+        v.MaxLen("description", req.Description, maxDescriptionLen)
+    }
+    if v.Required("serviceType", req.ServiceType) {
+        v.OneOf("serviceType", req.ServiceType, serviceTypes...)
+    }
+    checkID(v, "organizationId", req.OrganizationID)
+    checkUsername(v, "creatorUsername", req.CreatorUsername, true)
+    if req.Budget != nil {
+        checkMoney(v, "budget", *req.Budget)
+    }
+    if req.Auction != nil {
+        req.Auction.validate(v)
+        if req.Sealed {
+            v.Add("sealed", "cannot be combined with auction")
+        }
+    }
+    checkCriteria(v, req.Criteria)
+    if len(req.Lots) > maxLots {
+        v.Add("lots", fmt.Sprintf("must have at most %d items", maxLots))
+    }
+    for i, l := range req.Lots {
+        l.validate(v, fmt.Sprintf("lots[%d].", i))
+    }
+    if len(req.Lots) > 0 && req.Auction != nil {
+        v.Add("lots", "cannot be combined with auction")
+    }
+}
+
+// editTenderRequest holds optional fields; nil means unchanged.
+type editTenderRequest struct {
+    Name               *string            `json:"name"`
+    Description        *string            `json:"description"`
+    ServiceType        *string            `json:"serviceType"`
+    Budget             *model.Money       `json:"budget"`
+    SubmissionDeadline *time.Time         `json:"submissionDeadline"`
+    DecisionDeadline   *time.Time         `json:"decisionDeadline"`
+    Criteria           []criterionRequest `json:"criteria"`
+}
+
+func (req editTenderRequest) validate(v *validate.Validator) {
+    if req.Name != nil && v.Required("name", *req.Name) {
+        v.MaxLen("name", *req.Name, maxNameLen)
+    }
+    if req.Description != nil && v.Required("description", *req.Description) {
+        v.MaxLen("description", *req.Description, maxDescriptionLen)
+    }
+    if req.ServiceType != nil {
+        v.OneOf("serviceType", *req.ServiceType, serviceTypes...)
+    }
+    if req.Budget != nil {
+        checkMoney(v, "budget", *req.Budget)
+    }
+    checkCriteria(v, req.Criteria)
+}
+
 func (h *TenderHandler) list(w http.ResponseWriter, r *http.Request) {
     if r.Method != http.MethodGet {
-        http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+        writeReason(w, http.StatusMethodNotAllowed, "method not allowed")
         return
     }
-    res := h.svc.List(r.URL.Query()["service_type"])
-    writeJSON(w, http.StatusOK, res)
+    page, err := parsePage(r)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    q := r.URL.Query()
+    v := &validate.Validator{}
+    checkEnumList(v, "service_type", q["service_type"], serviceTypes)
+    checkUsername(v, "username", q.Get("username"), false)
+    history := checkInclude(v, q)
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    res, next, err := h.svc.List(q["service_type"], q.Get("username"), page)
+    if err == nil && history {
+        res, err = h.svc.WithHistory(res...)
+    }
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writePage(w, res, next)
 }
 
 func (h *TenderHandler) create(w http.ResponseWriter, r *http.Request) {
     if r.Method != http.MethodPost {
-        http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+        writeReason(w, http.StatusMethodNotAllowed, "method not allowed")
         return
     }
-    var req struct {
-        Name            string `json:"name"`
-        Description     string `json:"description"`
-        ServiceType     string `json:"serviceType"`
-        OrganizationID  string `json:"organizationId"`
-        CreatorUsername string `json:"creatorUsername"`
+    var req createTenderRequest
+    if err := decodeJSON(r, &req); err != nil {
+        writeError(w, err)
+        return
     }
-    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-        http.Error(w, "bad request", http.StatusBadRequest)
+    v := &validate.Validator{}
+    req.validate(v)
+    if err := v.Err(); err != nil {
+        writeError(w, err)
         return
     }
-    t, err := h.svc.Create(req.Name, req.Description, req.ServiceType, req.OrganizationID, req.CreatorUsername)
+    deadlines := service.Deadlines{Submission: req.SubmissionDeadline, Decision: req.DecisionDeadline}
+    var auction *model.Auction
+    if a := req.Auction; a != nil {
+        auction = &model.Auction{StartsAt: a.StartsAt, EndsAt: a.EndsAt, Step: a.Step, ExtensionSeconds: a.ExtensionSeconds}
+    }
+    t, err := h.svc.WithRequestID(requestID(r)).Create(req.Name, req.Description, req.ServiceType, req.OrganizationID, req.CreatorUsername,
+        req.Budget, deadlines, req.Sealed, auction, toCriteria(req.Criteria), toLots(req.Lots))
     if err != nil {
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+        writeError(w, err)
         return
     }
-    writeJSON(w, http.StatusOK, t)
+    writeVersioned(w, t.Version, t)
 }
 
 func (h *TenderHandler) userTenders(w http.ResponseWriter, r *http.Request) {
     if r.Method != http.MethodGet {
-        http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+        writeReason(w, http.StatusMethodNotAllowed, "method not allowed")
         return
     }
-    res := h.svc.UserTenders(r.URL.Query().Get("username"))
-    writeJSON(w, http.StatusOK, res)
+    page, err := parsePage(r)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    username := r.URL.Query().Get("username")
+    v := &validate.Validator{}
+    checkUsername(v, "username", username, true)
+    history := checkInclude(v, r.URL.Query())
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    res, next, err := h.svc.UserTenders(username, page)
+    if err == nil && history {
+        res, err = h.svc.WithHistory(res...)
+    }
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writePage(w, res, next)
 }
 
 func (h *TenderHandler) status(w http.ResponseWriter, r *http.Request) {
     id := chi.URLParam(r, "id")
+    q := r.URL.Query()
+    v := &validate.Validator{}
+    checkID(v, "tenderId", id)
+    checkUsername(v, "username", q.Get("username"), r.Method == http.MethodPut)
+    if r.Method == http.MethodPut && v.Required("status", q.Get("status")) {
+        v.OneOf("status", q.Get("status"), tenderStatuses...)
+    }
+    history := checkInclude(v, q)
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
     switch r.Method {
     case http.MethodGet:
-        tList := h.svc.List(nil)
-        var status string
-        for _, t := range tList {
-            if t.ID == id {
-                status = t.Status
-                break
-            }
+        tender, err := h.svc.Get(id, q.Get("username"))
+        if err != nil {
+            writeError(w, err)
+            return
         }
-        writeJSON(w, http.StatusOK, map[string]string{"status": status})
+        writeVersioned(w, tender.Version, map[string]string{"status": tender.Status})
     case http.MethodPut:
-        status := r.URL.Query().Get("status")
-        if status == "" {
-            http.Error(w, "missing status", http.StatusBadRequest)
-            return
+        tender, err := h.svc.WithRequestID(requestID(r)).UpdateStatus(id, q.Get("status"), q.Get("username"), ifMatch(r))
+        if err == nil && history {
+            tender, err = h.withHistory(tender)
         }
-        tender, err := h.svc.UpdateStatus(id, status)
         if err != nil {
-            http.Error(w, err.Error(), http.StatusInternalServerError)
+            writeError(w, err)
             return
         }
-        writeJSON(w, http.StatusOK, tender)
+        writeVersioned(w, tender.Version, tender)
     default:
-        http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+        writeReason(w, http.StatusMethodNotAllowed, "method not allowed")
     }
 }
 
 func (h *TenderHandler) edit(w http.ResponseWriter, r *http.Request) {
     if r.Method != http.MethodPatch {
-        http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+        writeReason(w, http.StatusMethodNotAllowed, "method not allowed")
         return
     }
     id := chi.URLParam(r, "id")
-    var req struct {
-        Name        *string `json:"name"`
-        Description *string `json:"description"`
-        ServiceType *string `json:"serviceType"`
+    username := r.URL.Query().Get("username")
+    var req editTenderRequest
+    if err := decodeJSON(r, &req); err != nil {
+        writeError(w, err)
+        return
     }
-    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-        http.Error(w, "bad request", http.StatusBadRequest)
+    v := &validate.Validator{}
+    checkID(v, "tenderId", id)
+    checkUsername(v, "username", username, true)
+    req.validate(v)
+    history := checkInclude(v, r.URL.Query())
+    if err := v.Err(); err != nil {
+        writeError(w, err)
         return
     }
-    tender, err := h.svc.Edit(id, req.Name, req.Description, req.ServiceType)
+    deadlines := service.Deadlines{Submission: req.SubmissionDeadline, Decision: req.DecisionDeadline}
+    tender, err := h.svc.WithRequestID(requestID(r)).Edit(id, username, ifMatch(r), req.Name, req.Description, req.ServiceType, req.Budget,
+        deadlines, toCriteria(req.Criteria))
+    if err == nil && history {
+        tender, err = h.withHistory(tender)
+    }
     if err != nil {
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+        writeError(w, err)
         return
     }
-    writeJSON(w, http.StatusOK, tender)
+    writeVersioned(w, tender.Version, tender)
 }
 
 func (h *TenderHandler) rollback(w http.ResponseWriter, r *http.Request) {
     if r.Method != http.MethodPut {
-        http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+        writeReason(w, http.StatusMethodNotAllowed, "method not allowed")
         return
     }
     id := chi.URLParam(r, "id")
-    v, _ := strconv.Atoi(chi.URLParam(r, "version"))
-    tender, err := h.svc.Rollback(id, v)
+    username := r.URL.Query().Get("username")
+    v := &validate.Validator{}
+    checkID(v, "tenderId", id)
+    checkUsername(v, "username", username, true)
+    ver := checkVersion(v, "version", chi.URLParam(r, "version"))
+    history := checkInclude(v, r.URL.Query())
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    tender, err := h.svc.WithRequestID(requestID(r)).Rollback(id, ver, username, ifMatch(r))
+    if err == nil && history {
+        tender, err = h.withHistory(tender)
+    }
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writeVersioned(w, tender.Version, tender)
+}
+
+// open reveals the bids of a sealed tender.
+func (h *TenderHandler) open(w http.ResponseWriter, r *http.Request) {
+    id := chi.URLParam(r, "id")
+    username := r.URL.Query().Get("username")
+    v := &validate.Validator{}
+    checkID(v, "tenderId", id)
+    checkUsername(v, "username", username, true)
+    history := checkInclude(v, r.URL.Query())
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    tender, err := h.svc.WithRequestID(requestID(r)).Open(id, username, ifMatch(r))
+    if err == nil && history {
+        tender, err = h.withHistory(tender)
+    }
     if err != nil {
-        http.Error(w, err.Error(), http.StatusInternalServerError)
+        writeError(w, err)
         return
     }
-    writeJSON(w, http.StatusOK, tender)
+    writeVersioned(w, tender.Version, tender)
 }
 
+func (h *TenderHandler) versions(w http.ResponseWriter, r *http.Request) {
+    id := chi.URLParam(r, "id")
+    username := r.URL.Query().Get("username")
+    page, err := parsePage(r)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    v := &validate.Validator{}
+    checkID(v, "tenderId", id)
+    checkUsername(v, "username", username, false)
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    res, next, err := h.svc.Versions(id, username, page)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writePage(w, res, next)
+}
+
+func (h *TenderHandler) version(w http.ResponseWriter, r *http.Request) {
+    id := chi.URLParam(r, "id")
+    username := r.URL.Query().Get("username")
+    v := &validate.Validator{}
+    checkID(v, "tenderId", id)
+    checkUsername(v, "username", username, false)
+    ver := checkVersion(v, "version", chi.URLParam(r, "version"))
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    res, err := h.svc.Version(id, ver, username)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writeJSON(w, http.StatusOK, res)
+}
+
+func (h *TenderHandler) diff(w http.ResponseWriter, r *http.Request) {
+    id := chi.URLParam(r, "id")
+    q := r.URL.Query()
+    v := &validate.Validator{}
+    checkID(v, "tenderId", id)
+    checkUsername(v, "username", q.Get("username"), false)
+    from, to := checkVersionRange(v, q)
+    if err := v.Err(); err != nil {
+        writeError(w, err)
+        return
+    }
+    res, err := h.svc.Diff(id, from, to, q.Get("username"))
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writeJSON(w, http.StatusOK, res)
+}
+
+// withHistory fills in the version history of t for ?include=history.
+func (h *TenderHandler) withHistory(t model.Tender) (model.Tender, error) {
+    res, err := h.svc.WithHistory(t)
+    if err != nil {
+        return model.Tender{}, err
+    }
+    return res[0], nil
+}
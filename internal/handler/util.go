@@ -1,7 +1,13 @@
 package handler
 
-import "net/http"
-import "encoding/json"
+import (
+    "encoding/json"
+    "net/http"
+    "strconv"
+    "strings"
+
+    "tender/internal/service"
+)
 
 func writeJSON(w http.ResponseWriter, code int, v any) {
     w.Header().Set("Content-Type", "application/json")
@@ -11,3 +17,56 @@ func writeJSON(w http.ResponseWriter, code int, v any) {
     }
 }
 
+// writeVersioned writes an entity response with its version as a strong
+// ETag, to be echoed back in If-Match on the next write.
+func writeVersioned(w http.ResponseWriter, version int, v any) {
+    w.Header().Set("ETag", strconv.Quote(strconv.Itoa(version)))
+    writeJSON(w, http.StatusOK, v)
+}
+
+// ifMatch reads the version expected by a conditional write from the
+// If-Match header. A missing header or "*" matches any version; a header
+// that is not one of our ETags matches none.
+func ifMatch(r *http.Request) int {
+    h := strings.TrimSpace(r.Header.Get("If-Match"))
+    if h == "" || h == "*" {
+        return service.AnyVersion
+    }
+    tag, err := strconv.Unquote(strings.TrimPrefix(h, "W/"))
+    if err != nil {
+        return -1
+    }
+    ver, err := strconv.Atoi(tag)
+    if err != nil || ver < 1 {
+        return -1
+    }
+    return ver
+}
+
+// parsePage reads the limit, offset and cursor query parameters. A missing
+// limit defaults to service.DefaultLimit.
+func parsePage(r *http.Request) (service.Page, error) {
+    q := r.URL.Query()
+    p := service.Page{Limit: service.DefaultLimit, Cursor: q.Get("cursor")}
+    var err error
+    if v := q.Get("limit"); v != "" {
+        if p.Limit, err = strconv.Atoi(v); err != nil {
+            return p, service.ErrInvalidPage
+        }
+    }
+    if v := q.Get("offset"); v != "" {
+        if p.Offset, err = strconv.Atoi(v); err != nil {
+            return p, service.ErrInvalidPage
+        }
+    }
+    return p, nil
+}
+
+// writePage writes a list response, advertising the next page cursor in the
+// X-Next-Cursor header when there is one.
+func writePage(w http.ResponseWriter, v any, next string) {
+    if next != "" {
+        w.Header().Set("X-Next-Cursor", next)
+    }
+    writeJSON(w, http.StatusOK, v)
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+    "encoding/json"
+    "errors"
+    "io"
+    "net/http"
+    "net/url"
+    "slices"
+    "strconv"
+
+    "tender/internal/model"
+    "tender/internal/service"
+    "tender/internal/validate"
+)
+
+// Limits from the component schemas of openapi.yml.
+const (
+    maxNameLen        = 100
+    maxDescriptionLen = 500
+    maxFeedbackLen    = 1000
+    maxUsernameLen    = 50
+)
+
+// Limits of evaluation criteria and lots.
+const (
+    maxCriteria = 20
+    maxWeight   = 100
+    maxLots     = 100
+)
+
+var (
+    serviceTypes   = []string{model.ServiceConstruction, model.ServiceDelivery, model.ServiceManufacture}
+    authorTypes    = []string{model.AuthorOrganization, model.AuthorUser}
+    tenderStatuses = []string{model.TenderCreated, model.TenderPublished, model.TenderClosed}
+    bidStatuses    = []string{model.BidCreated, model.BidPublished, model.BidCanceled}
+    bidDecisions   = []string{model.DecisionApproved, model.DecisionRejected}
+    includes       = []string{includeHistory}
+    bidOrders      = []string{service.BidsByName, service.BidsByPrice}
+    criterionKinds = []string{model.CriterionScore, model.CriterionPrice}
+    lotStatuses    = []string{model.LotOpen, model.LotAwarded, model.LotCanceled}
+    auditEntities  = []string{model.AuditTender, model.AuditBid, model.AuditQuestion}
+    auditActions   = []string{
+        model.AuditTenderCreate, model.AuditTenderEdit, model.AuditTenderStatus, model.AuditTenderOpen,
+        model.AuditTenderRollback, model.AuditTenderClose, model.AuditTenderAuction, model.AuditTenderExtend,
+        model.AuditTenderAttach, model.AuditTenderDetach, model.AuditLotAdd, model.AuditLotStatus, model.AuditLotAward,
+        model.AuditBidCreate, model.AuditBidEdit, model.AuditBidStatus, model.AuditBidPrice, model.AuditBidVote,
+        model.AuditBidDecision, model.AuditBidReject, model.AuditBidAward, model.AuditBidUnaward, model.AuditBidUndecide,
+        model.AuditBidFeedback, model.AuditBidEvaluate, model.AuditBidRollback, model.AuditBidAttach, model.AuditBidDetach,
+        model.AuditQuestionAsk, model.AuditQuestionAnswer, model.AuditQuestionUnanswer,
+    }
+)
+
+// includeHistory is the include value that adds version history to tenders
+// and bids in a response.
+const includeHistory = "history"
+
+// decodeJSON decodes the request body into v. Unknown fields, malformed
+// JSON and trailing data are reported as validation errors on "body".
+func decodeJSON(r *http.Request, v any) error {
+    dec := json.NewDecoder(r.Body)
+    dec.DisallowUnknownFields()
+    if err := dec.Decode(v); err != nil {
+        return validate.Errors{{Field: "body", Reason: err.Error()}}
+    }
+    if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+        return validate.Errors{{Field: "body", Reason: "must contain a single JSON object"}}
+    }
+    return nil
+}
+
+// checkID validates an identifier assigned by the server.
+func checkID(v *validate.Validator, field, value string) {
+    if v.Required(field, value) {
+        v.UUID(field, value)
+    }
+}
+
+func checkUsername(v *validate.Validator, field, value string, required bool) {
+    if value == "" {
+        if required {
+            v.Required(field, value)
+        }
+        return
+    }
+    v.MaxLen(field, value, maxUsernameLen)
+}
+
+// checkVersion parses a rollback version, which must be a positive int32.
+func checkVersion(v *validate.Validator, field, raw string) int {
+    n, err := strconv.ParseInt(raw, 10, 32)
+    if err != nil {
+        v.Add(field, "must be an integer")
+        return 0
+    }
+    v.Positive(field, int(n))
+    return int(n)
+}
+
+// checkInclude validates the include query parameter and reports whether
+// it asks for version history.
+func checkInclude(v *validate.Validator, q url.Values) bool {
+    checkEnumList(v, "include", q["include"], includes)
+    return slices.Contains(q["include"], includeHistory)
+}
+
+// checkVersionRange validates the from and to query parameters of a diff.
+func checkVersionRange(v *validate.Validator, q url.Values) (from, to int) {
+    from = checkVersion(v, "from", q.Get("from"))
+    to = checkVersion(v, "to", q.Get("to"))
+    if from > to {
+        v.Add("from", "must not be greater than to")
+    }
+    return from, to
+}
+
+// checkEnumList validates every value of a repeated query parameter.
+func checkEnumList(v *validate.Validator, field string, values, allowed []string) {
+    for _, s := range values {
+        v.OneOf(field, s, allowed...)
+    }
+}
+
+// checkBidSort validates the sort query parameter of bid lists and returns
+// the order it asks for, by name unless given.
+func checkBidSort(v *validate.Validator, q url.Values) string {
+    order := q.Get("sort")
+    if order == "" {
+        return service.BidsByName
+    }
+    v.OneOf("sort", order, bidOrders...)
+    return order
+}
+
+// checkMoney rejects an amount that is not positive or does not fit its
+// currency.
+func checkMoney(v *validate.Validator, field string, m model.Money) {
+    if m.Amount <= 0 {
+        v.Add(field, "must be positive")
+    } else if err := m.Validate(); err != nil {
+        v.Add(field, err.Error())
+    }
+}
+
+// parsePrice validates a price given as query parameters: an amount and an
+// optional currency, DefaultCurrency when left out.
+func parsePrice(v *validate.Validator, field, raw, currency string) model.Money {
+    if !v.Required(field, raw) {
+        return model.Money{}
+    }
+    amount, err := model.ParseAmount(raw)
+    if err != nil {
+        v.Add(field, err.Error())
+        return model.Money{}
+    }
+    if currency == "" {
+        currency = model.DefaultCurrency
+    }
+    price := model.Money{Amount: amount, Currency: currency}
+    checkMoney(v, field, price)
+    return price
+}
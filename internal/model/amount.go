@@ -0,0 +1,87 @@
+package model
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// AmountDecimals is the number of fractional digits an Amount keeps.
+const AmountDecimals = 4
+
+const amountScale = 10000
+
+// maxAmountDigits bounds the integer part so that every parsed amount fits
+// in an int64 of ten-thousandths.
+const maxAmountDigits = 14
+
+var errAmount = errors.New("must be a decimal number with at most 4 fractional digits")
+
+// Amount is an exact decimal amount of money, counted in ten-thousandths of
+// a unit. In JSON it is written as a decimal string such as "1234.5"; plain
+// JSON numbers are accepted as well and parsed without going through
+// floating point.
+type Amount int64
+
+// ParseAmount parses a decimal such as "1234.50" or "-3".
+func ParseAmount(s string) (Amount, error) {
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	whole, frac, dot := strings.Cut(s, ".")
+	if whole == "" || len(whole) > maxAmountDigits || (dot && frac == "") || len(frac) > AmountDecimals ||
+		!digits(whole) || !digits(frac) {
+		return 0, errAmount
+	}
+	frac += strings.Repeat("0", AmountDecimals-len(frac))
+	w, _ := strconv.ParseInt(whole, 10, 64)
+	f, _ := strconv.ParseInt(frac, 10, 64)
+	a := Amount(w*amountScale + f)
+	if neg {
+		a = -a
+	}
+	return a, nil
+}
+
+func digits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String formats a as a decimal without trailing fractional zeros.
+func (a Amount) String() string {
+	sign := ""
+	if a < 0 {
+		sign, a = "-", -a
+	}
+	s := strconv.FormatInt(int64(a/amountScale), 10)
+	if f := int64(a % amountScale); f != 0 {
+		s += "." + strings.TrimRight(strconv.FormatInt(f+amountScale, 10)[1:], "0")
+	}
+	return sign + s
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return strconv.AppendQuote(nil, a.String()), nil
+}
+
+func (a *Amount) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	if s, err := strconv.Unquote(string(b)); err == nil {
+		b = []byte(s)
+	} else if bytes.HasPrefix(b, []byte(`"`)) {
+		return err
+	}
+	v, err := ParseAmount(string(b))
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
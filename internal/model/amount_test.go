@@ -0,0 +1,163 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	cases := []struct {
+		in   string
+		want Amount
+		ok   bool
+	}{
+		{"0", 0, true},
+		{"-0", 0, true},
+		{"0.0000", 0, true},
+		{"1", 10000, true},
+		{"1234.5", 12345000, true},
+		{"1234.50", 12345000, true},
+		{"0.0001", 1, true},
+		{"0.1234", 1234, true},
+		{"-3", -30000, true},
+		{"-0.0001", -1, true},
+		{"007.10", 71000, true},
+		// The largest whole part, which still fits in ten-thousandths.
+		{"99999999999999.9999", 999999999999999999, true},
+		{"-99999999999999.9999", -999999999999999999, true},
+
+		// Amounts are never rounded: a fifth digit is refused even if zero.
+		{"0.00001", 0, false},
+		{"1.23456", 0, false},
+		{"1.00000", 0, false},
+		// A whole part of 15 digits could overflow int64.
+		{"100000000000000", 0, false},
+		{"922337203685477.5807", 0, false},
+		{"", 0, false},
+		{"-", 0, false},
+		{".5", 0, false},
+		{"5.", 0, false},
+		{"--5", 0, false},
+		{"+5", 0, false},
+		{"1e3", 0, false},
+		{"1,5", 0, false},
+		{" 1", 0, false},
+		{"1.2.3", 0, false},
+		{"0x10", 0, false},
+	}
+	for _, c := range cases {
+		got, err := ParseAmount(c.in)
+		if (err == nil) != c.ok || got != c.want {
+			t.Errorf("ParseAmount(%q) = %d, %v; want %d, ok %v", c.in, got, err, c.want, c.ok)
+		}
+	}
+}
+
+func TestAmountString(t *testing.T) {
+	cases := []struct {
+		in   Amount
+		want string
+	}{
+		{0, "0"},
+		{1, "0.0001"},
+		{10, "0.001"},
+		{12345000, "1234.5"},
+		{12345678, "1234.5678"},
+		{10000, "1"},
+		{-1, "-0.0001"},
+		{-30000, "-3"},
+		{-12345, "-1.2345"},
+		{999999999999999999, "99999999999999.9999"},
+	}
+	for _, c := range cases {
+		got := c.in.String()
+		if got != c.want {
+			t.Errorf("Amount(%d).String() = %q, want %q", c.in, got, c.want)
+		}
+		// Every formatted amount parses back to itself.
+		back, err := ParseAmount(got)
+		if err != nil || back != c.in {
+			t.Errorf("ParseAmount(%q) = %d, %v; want %d", got, back, err, c.in)
+		}
+	}
+}
+
+func TestAmountJSON(t *testing.T) {
+	cases := []struct {
+		in   string
+		want Amount
+		ok   bool
+	}{
+		{`"1234.5"`, 12345000, true},
+		// Numbers are read as written, without floating point.
+		{`1234.5678`, 12345678, true},
+		{`0.1`, 1000, true},
+		{`-2`, -20000, true},
+		{`"1.23456"`, 0, false},
+		{`1.23456`, 0, false},
+		{`1e2`, 0, false},
+		{`"1234.5`, 0, false},
+		{`true`, 0, false},
+	}
+	for _, c := range cases {
+		var got Amount
+		err := json.Unmarshal([]byte(c.in), &got)
+		if (err == nil) != c.ok || got != c.want {
+			t.Errorf("unmarshal %s = %d, %v; want %d, ok %v", c.in, got, err, c.want, c.ok)
+		}
+		if !c.ok {
+			continue
+		}
+		b, err := json.Marshal(got)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var back Amount
+		if err := json.Unmarshal(b, &back); err != nil || back != got {
+			t.Errorf("round trip of %s through %s = %d, %v", c.in, b, back, err)
+		}
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	var m Money
+	if err := json.Unmarshal([]byte(`{"amount": "10.25", "currency": "USD"}`), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m != (Money{Amount: 102500, Currency: "USD"}) {
+		t.Fatalf("got %v", m)
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"amount":"10.25","currency":"USD"}` {
+		t.Fatalf("marshaled as %s", b)
+	}
+	// Bare amounts were stored before money carried a currency.
+	if err := json.Unmarshal([]byte(`"7"`), &m); err != nil || m != (Money{Amount: 70000, Currency: DefaultCurrency}) {
+		t.Fatalf("legacy amount read as %v, %v", m, err)
+	}
+}
+
+func TestMoneyValidate(t *testing.T) {
+	cases := []struct {
+		m  Money
+		ok bool
+	}{
+		{Money{Amount: 12345000, Currency: "RUB"}, true},
+		{Money{Amount: 12345100, Currency: "RUB"}, true},
+		{Money{Amount: 12345110, Currency: "RUB"}, false},
+		{Money{Amount: 10000, Currency: "JPY"}, true},
+		{Money{Amount: 15000, Currency: "JPY"}, false},
+		{Money{Amount: 1, Currency: "CLF"}, true},
+		{Money{Amount: -10, Currency: "KWD"}, true},
+		{Money{Amount: 0, Currency: "XXX"}, false},
+		{Money{Amount: 0, Currency: "rub"}, false},
+	}
+	for _, c := range cases {
+		if err := c.m.Validate(); (err == nil) != c.ok {
+			t.Errorf("%v: Validate() = %v, want ok %v", c.m, err, c.ok)
+		}
+	}
+}
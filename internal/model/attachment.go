@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// Attachment describes a document attached to a tender or bid. Its content
+// lives in the blob store under its SHA256, so every version carrying the
+// attachment keeps it downloadable.
+type Attachment struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256"`
+	ContentType string    `json:"contentType"`
+	UploadedBy  string    `json:"uploadedBy,omitempty"`
+	UploadedAt  time.Time `json:"uploadedAt"`
+}
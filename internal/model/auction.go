@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// Auction turns a tender into a reverse auction. Between StartsAt and EndsAt
+// bidders keep lowering their prices; every new price must be in the
+// currency of Step and at least Step below the best price so far. A price
+// submitted less than ExtensionSeconds before the end moves EndsAt to that
+// long after it.
+//
+// BestPrice and BestBidID track the leading price and are maintained by the
+// auction, never set by clients.
+type Auction struct {
+	StartsAt         time.Time `json:"startsAt"`
+	EndsAt           time.Time `json:"endsAt"`
+	Step             Money     `json:"step"`
+	ExtensionSeconds int       `json:"extensionSeconds,omitempty"`
+	BestPrice        *Money    `json:"bestPrice,omitempty"`
+	BestBidID        string    `json:"bestBidId,omitempty"`
+}
+
+// Extension returns ExtensionSeconds as a duration.
+func (a Auction) Extension() time.Duration {
+	return time.Duration(a.ExtensionSeconds) * time.Second
+}
+
+// Started reports whether the auction accepts prices at now, its end
+// aside.
+func (a Auction) Started(now time.Time) bool {
+	return !now.Before(a.StartsAt)
+}
+
+// Standing is a bid's place in an auction.
+type Standing struct {
+	Rank       int       `json:"rank"`
+	BidID      string    `json:"bidId"`
+	AuthorType string    `json:"authorType"`
+	AuthorID   string    `json:"authorId"`
+	Price      Money     `json:"price"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
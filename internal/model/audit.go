@@ -0,0 +1,110 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// Entity types of audit events.
+const (
+	AuditTender   = "tender"
+	AuditBid      = "bid"
+	AuditQuestion = "question"
+)
+
+// Actions of audit events.
+const (
+	AuditTenderCreate   = "tender.create"
+	AuditTenderEdit     = "tender.edit"
+	AuditTenderStatus   = "tender.status"
+	AuditTenderOpen     = "tender.open"
+	AuditTenderRollback = "tender.rollback"
+	AuditTenderClose    = "tender.close"
+	AuditTenderAuction  = "tender.auction"
+	AuditTenderExtend   = "tender.extend"
+	AuditTenderAttach   = "tender.attach"
+	AuditTenderDetach   = "tender.detach"
+	AuditLotAdd         = "lot.add"
+	AuditLotStatus      = "lot.status"
+	AuditLotAward       = "lot.award"
+
+	AuditBidCreate   = "bid.create"
+	AuditBidEdit     = "bid.edit"
+	AuditBidStatus   = "bid.status"
+	AuditBidPrice    = "bid.price"
+	AuditBidVote     = "bid.vote"
+	AuditBidDecision = "bid.decision"
+	AuditBidReject   = "bid.reject"
+	AuditBidAward    = "bid.award"
+	AuditBidUnaward  = "bid.unaward"
+	AuditBidUndecide = "bid.undecide"
+	AuditBidFeedback = "bid.feedback"
+	AuditBidEvaluate = "bid.evaluate"
+	AuditBidRollback = "bid.rollback"
+	AuditBidAttach   = "bid.attach"
+	AuditBidDetach   = "bid.detach"
+
+	AuditQuestionAsk      = "question.ask"
+	AuditQuestionAnswer   = "question.answer"
+	AuditQuestionUnanswer = "question.unanswer"
+)
+
+// AuditEvent is an entry of the append-only audit log: Actor, empty for
+// anonymous users and the system, performed Action on an entity of the
+// tender TenderID, taking it from FromVersion to ToVersion. Actions that do
+// not make a new version, such as votes, have both set to the version they
+// applied to. Questions count their own versions: asking one goes from 0
+// to 1, and every answer or taken-back answer adds one.
+//
+// Events are numbered from 1 by Seq and hash-chained: Hash covers the event
+// and PrevHash, the Hash of the event before it, so changing, removing or
+// reordering any event breaks every hash after it.
+type AuditEvent struct {
+	Seq         int64     `json:"seq"`
+	At          time.Time `json:"at"`
+	Actor       string    `json:"actor,omitempty"`
+	Action      string    `json:"action"`
+	EntityType  string    `json:"entityType"`
+	EntityID    string    `json:"entityId"`
+	TenderID    string    `json:"tenderId"`
+	FromVersion int       `json:"fromVersion"`
+	ToVersion   int       `json:"toVersion"`
+	RequestID   string    `json:"requestId,omitempty"`
+	PrevHash    string    `json:"prevHash"`
+	Hash        string    `json:"hash"`
+}
+
+// Chain returns e as the event after prev, the zero event for the first
+// one. At is kept in UTC to the microsecond, the precision every store
+// keeps, so the hash can be checked after a round trip.
+func (e AuditEvent) Chain(prev AuditEvent) AuditEvent {
+	e.Seq = prev.Seq + 1
+	e.At = e.At.UTC().Truncate(time.Microsecond)
+	e.PrevHash = prev.Hash
+	e.Hash = e.ComputeHash()
+	return e
+}
+
+// ComputeHash returns the hex SHA-256 of the JSON array of the fields of e
+// but Hash.
+func (e AuditEvent) ComputeHash() string {
+	fields, _ := json.Marshal([]any{
+		e.Seq, e.At.UTC().Format(time.RFC3339Nano), e.Actor, e.Action, e.EntityType, e.EntityID, e.TenderID,
+		e.FromVersion, e.ToVersion, e.RequestID, e.PrevHash,
+	})
+	sum := sha256.Sum256(fields)
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditVerification is the outcome of checking the audit chain. Events is
+// how many events from the first fit the chain and Head the hash of the
+// last of them. When the chain is broken BrokenAt is the position of the
+// first event that does not fit it.
+type AuditVerification struct {
+	Valid    bool   `json:"valid"`
+	Events   int64  `json:"events"`
+	Head     string `json:"head,omitempty"`
+	BrokenAt int64  `json:"brokenAt,omitempty"`
+}
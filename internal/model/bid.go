@@ -2,6 +2,33 @@ package model
 
 import "time"
 
+// Bid statuses. Created, Published and Canceled are set by the author through
+// the status endpoint; Approved and Rejected are the outcome of a decision.
+const (
+	BidCreated   = "Created"
+	BidPublished = "Published"
+	BidCanceled  = "Canceled"
+	BidApproved  = "Approved"
+	BidRejected  = "Rejected"
+)
+
+// Bid author types as defined by openapi.yml.
+const (
+	AuthorOrganization = "Organization"
+	AuthorUser         = "User"
+)
+
+// Bid decisions as defined by openapi.yml.
+const (
+	DecisionApproved = "Approved"
+	DecisionRejected = "Rejected"
+)
+
+// Bid is the current state of a bid. Past versions are kept in a separate
+// store; History is only filled in when a response asks for it. Sealed marks
+// a response from which the contents were withheld; it is never stored.
+// LotIDs are the lots of the tender the bid is for, when it has lots.
+// Attachments are versioned with the bid like its description.
 type Bid struct {
 	ID          string       `json:"id"`
 	Name        string       `json:"name"`
@@ -12,26 +39,86 @@ type Bid struct {
 	Status      string       `json:"status"`
 	Decision    string       `json:"decision,omitempty"`
 	Feedback    string       `json:"feedback,omitempty"`
+	Price       *Money       `json:"price,omitempty"`
+	LotIDs      []string     `json:"lotIds,omitempty"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+	Sealed      bool         `json:"sealed,omitempty"`
 	Version     int          `json:"version"`
 	CreatedAt   time.Time    `json:"createdAt"`
+	UpdatedBy   string       `json:"updatedBy,omitempty"`
+	UpdatedAt   time.Time    `json:"updatedAt"`
 	History     []BidVersion `json:"history,omitempty"`
 }
 
+// Seal returns b with its contents withheld, keeping its identity, author,
+// status and version, as shown while its tender's bids are sealed.
+func (b Bid) Seal() Bid {
+	return Bid{
+		ID:         b.ID,
+		TenderID:   b.TenderID,
+		AuthorType: b.AuthorType,
+		AuthorID:   b.AuthorID,
+		Status:     b.Status,
+		Sealed:     true,
+		Version:    b.Version,
+		CreatedAt:  b.CreatedAt,
+		UpdatedBy:  b.UpdatedBy,
+		UpdatedAt:  b.UpdatedAt,
+	}
+}
+
+// BidVersion is a snapshot of a bid's editable state. UpdatedBy and
+// UpdatedAt tell who produced the version and when.
 type BidVersion struct {
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Status      string    `json:"status"`
-	Decision    string    `json:"decision,omitempty"`
-	Feedback    string    `json:"feedback,omitempty"`
-	Version     int       `json:"version"`
-	CreatedAt   time.Time `json:"createdAt"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Status      string       `json:"status"`
+	Decision    string       `json:"decision,omitempty"`
+	Feedback    string       `json:"feedback,omitempty"`
+	Price       *Money       `json:"price,omitempty"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+	Version     int          `json:"version"`
+	CreatedAt   time.Time    `json:"createdAt"`
+	UpdatedBy   string       `json:"updatedBy,omitempty"`
+	UpdatedAt   time.Time    `json:"updatedAt"`
+}
+
+// Snapshot returns the current state of b as a version.
+func (b Bid) Snapshot() BidVersion {
+	return BidVersion{
+		Name:        b.Name,
+		Description: b.Description,
+		Status:      b.Status,
+		Decision:    b.Decision,
+		Feedback:    b.Feedback,
+		Price:       b.Price,
+		Attachments: b.Attachments,
+		Version:     b.Version,
+		CreatedAt:   b.CreatedAt,
+		UpdatedBy:   b.UpdatedBy,
+		UpdatedAt:   b.UpdatedAt,
+	}
+}
+
+// BidDecision is a single responsible's vote on a bid. The bid's own
+// Decision field holds the outcome once the quorum is reached.
+type BidDecision struct {
+	ID        string    `json:"id"`
+	BidID     string    `json:"bidId"`
+	UserID    string    `json:"userId"`
+	Username  string    `json:"username"`
+	Decision  string    `json:"decision"`
+	CreatedAt time.Time `json:"createdAt"`
 }
 
-// BidReview represents review on a bid.
+// BidReview is feedback a tender owner left on a bid. Reviews are tied to
+// the bid author so they can be consulted on the author's later bids.
 type BidReview struct {
-	ID             string    `json:"id"`
-	TenderID       string    `json:"tenderId"`
-	AuthorUsername string    `json:"authorUsername"`
-	Description    string    `json:"description"`
-	CreatedAt      time.Time `json:"createdAt"`
+	ID               string    `json:"id"`
+	BidID            string    `json:"bidId"`
+	TenderID         string    `json:"tenderId"`
+	AuthorID         string    `json:"authorId"`
+	ReviewerUsername string    `json:"reviewerUsername"`
+	Description      string    `json:"description"`
+	CreatedAt        time.Time `json:"createdAt"`
 }
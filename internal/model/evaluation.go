@@ -0,0 +1,60 @@
+package model
+
+import "time"
+
+// Criterion kinds. Evaluators score Score criteria by hand; Price criteria
+// are scored from the bid prices, the cheapest bid getting MaxScore.
+const (
+	CriterionScore = "Score"
+	CriterionPrice = "Price"
+)
+
+// MaxScore is the best score of a bid on a criterion. Scores range from 0
+// to MaxScore.
+const MaxScore = 10
+
+// Criterion is one of the criteria bids on a tender are evaluated by. The
+// weights of a tender's criteria are relative to each other.
+type Criterion struct {
+	Name   string `json:"name"`
+	Kind   string `json:"kind"`
+	Weight int    `json:"weight"`
+}
+
+// BidEvaluation holds the scores one evaluator gave a bid, by criterion
+// name. There is one per bid and evaluator; scoring the bid again replaces
+// the scores.
+type BidEvaluation struct {
+	ID                string         `json:"id"`
+	BidID             string         `json:"bidId"`
+	TenderID          string         `json:"tenderId"`
+	EvaluatorID       string         `json:"evaluatorId"`
+	EvaluatorUsername string         `json:"evaluatorUsername"`
+	Scores            map[string]int `json:"scores"`
+	CreatedAt         time.Time      `json:"createdAt"`
+	UpdatedAt         time.Time      `json:"updatedAt"`
+}
+
+// BidRanking is a bid's place in the evaluation of a tender. Scores holds
+// the bid's score on each criterion: the mean of its evaluators' scores, or
+// the normalized price score. Total is the weighted mean of Scores.
+type BidRanking struct {
+	Rank        int                `json:"rank"`
+	BidID       string             `json:"bidId"`
+	BidName     string             `json:"bidName"`
+	AuthorType  string             `json:"authorType"`
+	AuthorID    string             `json:"authorId"`
+	Status      string             `json:"status"`
+	Price       *Money             `json:"price,omitempty"`
+	Total       float64            `json:"total"`
+	Scores      map[string]float64 `json:"scores"`
+	Evaluations []EvaluatorScores  `json:"evaluations"`
+}
+
+// EvaluatorScores is one evaluator's part in a BidRanking: their scores
+// and the weighted total they would give the bid on their own.
+type EvaluatorScores struct {
+	Username string         `json:"username"`
+	Scores   map[string]int `json:"scores"`
+	Total    float64        `json:"total"`
+}
@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+// Lot statuses. Lots are Open until they are awarded to a bid or canceled.
+const (
+	LotOpen     = "Open"
+	LotAwarded  = "Awarded"
+	LotCanceled = "Canceled"
+)
+
+// Lot is an independently awarded part of a tender. Lots are stored with
+// their tender, so every change to a lot is a new version of the tender.
+// AwardedBidID names the winning bid of an Awarded lot.
+type Lot struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description"`
+	Quantity     int       `json:"quantity"`
+	Budget       *Money    `json:"budget,omitempty"`
+	Status       string    `json:"status"`
+	AwardedBidID string    `json:"awardedBidId,omitempty"`
+	UpdatedBy    string    `json:"updatedBy,omitempty"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// Lot returns the lot of t with the given ID.
+func (t Tender) Lot(id string) (Lot, bool) {
+	for _, l := range t.Lots {
+		if l.ID == id {
+			return l, true
+		}
+	}
+	return Lot{}, false
+}
+
+// LotsResolved reports whether every lot of t is awarded or canceled. A
+// tender without lots has none left open either.
+func (t Tender) LotsResolved() bool {
+	for _, l := range t.Lots {
+		if l.Status == LotOpen {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// Question is a clarification asked on a published tender. Until its
+// answer is published only the asker and the responsibles of the tender's
+// organization see it; once published everyone does, without AskedBy.
+// DeadlineExtended records the submission deadline the answer pushed the
+// tender to, if it was material enough to do so. Version counts the
+// changes of the question, starting at 1 when it is asked.
+type Question struct {
+	ID               string     `json:"id"`
+	TenderID         string     `json:"tenderId"`
+	Text             string     `json:"text"`
+	AskedBy          string     `json:"askedBy,omitempty"`
+	AskedAt          time.Time  `json:"askedAt"`
+	Answer           string     `json:"answer,omitempty"`
+	AnsweredBy       string     `json:"answeredBy,omitempty"`
+	AnsweredAt       *time.Time `json:"answeredAt,omitempty"`
+	Published        bool       `json:"published"`
+	DeadlineExtended *time.Time `json:"deadlineExtended,omitempty"`
+	Version          int        `json:"version"`
+}
+
+// Answered reports whether q has an answer.
+func (q Question) Answered() bool {
+	return q.AnsweredAt != nil
+}
@@ -2,24 +2,143 @@ package model
 
 import "time"
 
+// Tender statuses as defined by openapi.yml.
+const (
+	TenderCreated   = "Created"
+	TenderPublished = "Published"
+	TenderClosed    = "Closed"
+)
+
+// Tender service types as defined by openapi.yml.
+const (
+	ServiceConstruction = "Construction"
+	ServiceDelivery     = "Delivery"
+	ServiceManufacture  = "Manufacture"
+)
+
+// Tender is the current state of a tender. Past versions are kept in a
+// separate store; History is only filled in when a response asks for it.
+//
+// Bids are accepted until SubmissionDeadline and decided on until
+// DecisionDeadline; either may be nil for no deadline. The tender is closed
+// automatically once the last of them passes.
+//
+// The contents of bids on a Sealed tender are hidden until its submission
+// deadline passes or a responsible opens it, which sets OpenedBy and
+// OpenedAt.
+//
+// A tender with an Auction is a reverse auction whose end is its
+// submission deadline. Bids priced above the Budget, or in another
+// currency, are not accepted. Criteria, when set, are what bids are
+// evaluated by. A tender with Lots is awarded lot by lot instead of as a
+// whole. Attachments are versioned with the tender like its description.
 type Tender struct {
-	ID              string          `json:"id"`
-	Name            string          `json:"name"`
-	Description     string          `json:"description"`
-	ServiceType     string          `json:"serviceType"`
-	OrganizationID  string          `json:"organizationId"`
-	CreatorUsername string          `json:"creatorUsername"`
-	Status          string          `json:"status"`
-	Version         int             `json:"version"`
-	CreatedAt       time.Time       `json:"createdAt"`
-	History         []TenderVersion `json:"history,omitempty"`
+	ID                 string          `json:"id"`
+	Name               string          `json:"name"`
+	Description        string          `json:"description"`
+	ServiceType        string          `json:"serviceType"`
+	OrganizationID     string          `json:"organizationId"`
+	CreatorUsername    string          `json:"creatorUsername"`
+	Status             string          `json:"status"`
+	SubmissionDeadline *time.Time      `json:"submissionDeadline,omitempty"`
+	DecisionDeadline   *time.Time      `json:"decisionDeadline,omitempty"`
+	Sealed             bool            `json:"sealed,omitempty"`
+	OpenedBy           string          `json:"openedBy,omitempty"`
+	OpenedAt           *time.Time      `json:"openedAt,omitempty"`
+	Auction            *Auction        `json:"auction,omitempty"`
+	Budget             *Money          `json:"budget,omitempty"`
+	Criteria           []Criterion     `json:"criteria,omitempty"`
+	Lots               []Lot           `json:"lots,omitempty"`
+	Attachments        []Attachment    `json:"attachments,omitempty"`
+	Version            int             `json:"version"`
+	CreatedAt          time.Time       `json:"createdAt"`
+	UpdatedBy          string          `json:"updatedBy,omitempty"`
+	UpdatedAt          time.Time       `json:"updatedAt"`
+	History            []TenderVersion `json:"history,omitempty"`
 }
 
+// TenderVersion is a snapshot of a tender's editable state. UpdatedBy and
+// UpdatedAt tell who produced the version and when.
 type TenderVersion struct {
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	ServiceType string    `json:"serviceType"`
-	Status      string    `json:"status"`
-	Version     int       `json:"version"`
-	CreatedAt   time.Time `json:"createdAt"`
+	Name               string       `json:"name"`
+	Description        string       `json:"description"`
+	ServiceType        string       `json:"serviceType"`
+	Status             string       `json:"status"`
+	SubmissionDeadline *time.Time   `json:"submissionDeadline,omitempty"`
+	DecisionDeadline   *time.Time   `json:"decisionDeadline,omitempty"`
+	Sealed             bool         `json:"sealed,omitempty"`
+	OpenedBy           string       `json:"openedBy,omitempty"`
+	OpenedAt           *time.Time   `json:"openedAt,omitempty"`
+	Auction            *Auction     `json:"auction,omitempty"`
+	Budget             *Money       `json:"budget,omitempty"`
+	Criteria           []Criterion  `json:"criteria,omitempty"`
+	Lots               []Lot        `json:"lots,omitempty"`
+	Attachments        []Attachment `json:"attachments,omitempty"`
+	Version            int          `json:"version"`
+	CreatedAt          time.Time    `json:"createdAt"`
+	UpdatedBy          string       `json:"updatedBy,omitempty"`
+	UpdatedAt          time.Time    `json:"updatedAt"`
+}
+
+// Snapshot returns the current state of t as a version.
+func (t Tender) Snapshot() TenderVersion {
+	return TenderVersion{
+		Name:               t.Name,
+		Description:        t.Description,
+		ServiceType:        t.ServiceType,
+		Status:             t.Status,
+		SubmissionDeadline: t.SubmissionDeadline,
+		DecisionDeadline:   t.DecisionDeadline,
+		Sealed:             t.Sealed,
+		OpenedBy:           t.OpenedBy,
+		OpenedAt:           t.OpenedAt,
+		Auction:            t.Auction,
+		Budget:             t.Budget,
+		Criteria:           t.Criteria,
+		Lots:               t.Lots,
+		Attachments:        t.Attachments,
+		Version:            t.Version,
+		CreatedAt:          t.CreatedAt,
+		UpdatedBy:          t.UpdatedBy,
+		UpdatedAt:          t.UpdatedAt,
+	}
+}
+
+// SubmissionClosed reports whether the submission deadline of t has passed
+// at now.
+func (t Tender) SubmissionClosed(now time.Time) bool {
+	return t.SubmissionDeadline != nil && !now.Before(*t.SubmissionDeadline)
+}
+
+// DecisionClosed reports whether the decision deadline of t has passed at
+// now.
+func (t Tender) DecisionClosed(now time.Time) bool {
+	return t.DecisionDeadline != nil && !now.Before(*t.DecisionDeadline)
+}
+
+// ClosingDeadline returns the deadline after which nothing more can happen
+// on t: the decision deadline if it has one, otherwise the submission
+// deadline plus window, the time left for a decision. Without a decision
+// deadline and with no window, or without any deadline, it returns nil.
+func (t Tender) ClosingDeadline(window time.Duration) *time.Time {
+	if t.DecisionDeadline != nil {
+		return t.DecisionDeadline
+	}
+	if t.SubmissionDeadline == nil || window <= 0 {
+		return nil
+	}
+	at := t.SubmissionDeadline.Add(window)
+	return &at
+}
+
+// Expired reports whether the closing deadline of t, given window, has
+// passed at now.
+func (t Tender) Expired(now time.Time, window time.Duration) bool {
+	at := t.ClosingDeadline(window)
+	return at != nil && !now.Before(*at)
+}
+
+// BidsSealed reports whether the contents of bids on t are hidden at now.
+func (t Tender) BidsSealed(now time.Time) bool {
+	return t.Sealed && t.OpenedAt == nil && !t.SubmissionClosed(now)
 }
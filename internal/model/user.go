@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// Employee is a user of the service, identified in requests by username.
+type Employee struct {
+	ID        string    `json:"id"`
+	Username  string    `json:"username"`
+	FirstName string    `json:"firstName,omitempty"`
+	LastName  string    `json:"lastName,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+const (
+	OrganizationIE  = "IE"
+	OrganizationLLC = "LLC"
+	OrganizationJSC = "JSC"
+)
+
+type Organization struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Type        string    `json:"type,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// OrganizationResponsible links an employee to an organization they may act
+// on behalf of.
+type OrganizationResponsible struct {
+	ID             string `json:"id"`
+	OrganizationID string `json:"organizationId"`
+	UserID         string `json:"userId"`
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// FieldChange is one field that differs between two versions of a tender or
+// bid. Version, UpdatedBy and UpdatedAt identify the last change to the field
+// within the compared range.
+type FieldChange struct {
+	Field     string    `json:"field"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Version   int       `json:"version"`
+	UpdatedBy string    `json:"updatedBy,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// VersionDiff lists the fields that differ between versions From and To.
+type VersionDiff struct {
+	From    int           `json:"from"`
+	To      int           `json:"to"`
+	Changes []FieldChange `json:"changes"`
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+    "errors"
+
+    "tender/internal/model"
+    "tender/internal/storage"
+)
+
+// access resolves usernames against the employee directory and checks
+// organization responsibility.
+type access struct {
+    users storage.UserRepository
+}
+
+func (a access) employee(username string) (model.Employee, error) {
+    if username == "" {
+        return model.Employee{}, ErrUnauthorized
+    }
+    e, err := a.users.GetEmployee(username)
+    if errors.Is(err, storage.ErrNotFound) {
+        return model.Employee{}, ErrUnauthorized
+    }
+    return e, err
+}
+
+// responsible returns the employee behind username if they are responsible
+// for orgID, ErrUnauthorized if they do not exist and ErrForbidden otherwise.
+func (a access) responsible(username, orgID string) (model.Employee, error) {
+    e, err := a.employee(username)
+    if err != nil {
+        return model.Employee{}, err
+    }
+    if err := a.check(e, orgID); err != nil {
+        return model.Employee{}, err
+    }
+    return e, nil
+}
+
+func (a access) responsibles(orgID string) ([]model.Employee, error) {
+    return a.users.OrganizationResponsibles(orgID)
+}
+
+// organizations returns the IDs of the organizations e is responsible for.
+func (a access) organizations(e model.Employee) ([]string, error) {
+    return a.users.ResponsibleOrganizations(e.ID)
+}
+
+func (a access) isResponsible(e model.Employee, orgID string) (bool, error) {
+    return a.users.IsResponsible(orgID, e.ID)
+}
+
+// author returns ErrForbidden unless e may act for the author of b: the
+// author themselves, or a responsible of the authoring organization.
+func (a access) author(e model.Employee, b model.Bid) error {
+    switch b.AuthorType {
+    case model.AuthorUser:
+        if e.ID == b.AuthorID {
+            return nil
+        }
+    case model.AuthorOrganization:
+        return a.check(e, b.AuthorID)
+    }
+    return ErrForbidden
+}
+
+// known returns ErrUnauthorized unless authorID names an existing employee
+// or organization, as authorType says.
+func (a access) known(authorType, authorID string) error {
+    var err error
+    switch authorType {
+    case model.AuthorUser:
+        _, err = a.users.GetEmployeeByID(authorID)
+    case model.AuthorOrganization:
+        _, err = a.users.GetOrganization(authorID)
+    default:
+        return ErrUnauthorized
+    }
+    if errors.Is(err, storage.ErrNotFound) {
+        return ErrUnauthorized
+    }
+    return err
+}
+
+// check returns ErrForbidden unless e is responsible for orgID.
+func (a access) check(e model.Employee, orgID string) error {
+    ok, err := a.isResponsible(e, orgID)
+    if err != nil {
+        return err
+    }
+    if !ok {
+        return ErrForbidden
+    }
+    return nil
+}
@@ -0,0 +1,337 @@
+package service
+
+import (
+    "errors"
+    "fmt"
+    "io"
+    "slices"
+
+    "github.com/google/uuid"
+
+    "tender/internal/model"
+    "tender/internal/storage"
+    "tender/internal/storage/blob"
+)
+
+// Errors of attachments.
+var (
+    // ErrTooManyAttachments is returned for an attachment beyond
+    // MaxAttachments on a tender or bid.
+    ErrTooManyAttachments = errors.New("too many attachments")
+    // ErrAttachmentTooLarge is returned for an upload over the size limit.
+    ErrAttachmentTooLarge = errors.New("attachment too large")
+)
+
+// MaxAttachments bounds the attachments of one tender or bid.
+const MaxAttachments = 20
+
+// DefaultMaxAttachmentSize is the default size limit of an attachment.
+const DefaultMaxAttachmentSize = 20 << 20
+
+// Upload is a document to attach, read from Body.
+type Upload struct {
+    Name        string
+    ContentType string
+    Body        io.Reader
+}
+
+// AttachmentService attaches documents to tenders and bids. Attaching and
+// detaching make new versions of the entity; the contents stay in the blob
+// store, so every version keeps its attachments downloadable.
+type AttachmentService struct {
+    tenders  storage.TenderRepository
+    bids     storage.BidRepository
+    versions storage.VersionRepository
+    blobs    blob.Store
+    audit    auditor
+    access   access
+    clock    Clock
+
+    // MaxSize is the size limit of an attachment in bytes.
+    MaxSize int64
+}
+
+func NewAttachmentService(tenders storage.TenderRepository, bids storage.BidRepository, versions storage.VersionRepository, blobs blob.Store, users storage.UserRepository) *AttachmentService {
+    return &AttachmentService{
+        tenders:  tenders,
+        bids:     bids,
+        versions: versions,
+        blobs:    blobs,
+        access:   access{users: users},
+        clock:    SystemClock,
+        MaxSize:  DefaultMaxAttachmentSize,
+    }
+}
+
+// WithRequestID returns a copy of s that records its changes in the audit
+// log as made by the request id.
+func (s *AttachmentService) WithRequestID(id string) *AttachmentService {
+    c := *s
+    c.audit.requestID = id
+    return &c
+}
+
+// AttachToTender attaches a document to a tender on behalf of a
+// responsible of its organization. ifMatch is the version of the tender the
+// caller expects, or AnyVersion.
+func (s *AttachmentService) AttachToTender(tenderID, username string, ifMatch int, u Upload) (model.Tender, model.Attachment, error) {
+    user, err := s.access.employee(username)
+    if err != nil {
+        return model.Tender{}, model.Attachment{}, err
+    }
+    tender, err := getTender(s.tenders, tenderID)
+    if err != nil {
+        return model.Tender{}, model.Attachment{}, err
+    }
+    // Checked up front as well, so that nobody else can fill the store.
+    if err := s.access.check(user, tender.OrganizationID); err != nil {
+        return model.Tender{}, model.Attachment{}, err
+    }
+    a, err := s.save(u, user.Username)
+    if err != nil {
+        return model.Tender{}, model.Attachment{}, err
+    }
+    tender, err = s.audit.updateTender(s.tenders, model.AuditTenderAttach, user.Username, tenderID, ifMatch, func(t *model.Tender) error {
+        if err := s.access.check(user, t.OrganizationID); err != nil {
+            return err
+        }
+        attachments, err := attach(t.Attachments, a)
+        if err != nil {
+            return err
+        }
+        t.Attachments = attachments
+        bumpTender(t, user.Username, a.UploadedAt)
+        return nil
+    })
+    if err != nil {
+        return model.Tender{}, model.Attachment{}, err
+    }
+    return tender, a, nil
+}
+
+// DetachFromTender removes an attachment from a tender on behalf of a
+// responsible of its organization. Past versions keep it.
+func (s *AttachmentService) DetachFromTender(tenderID, attachmentID, username string, ifMatch int) (model.Tender, model.Attachment, error) {
+    user, err := s.access.employee(username)
+    if err != nil {
+        return model.Tender{}, model.Attachment{}, err
+    }
+    var a model.Attachment
+    tender, err := s.audit.updateTender(s.tenders, model.AuditTenderDetach, user.Username, tenderID, ifMatch, func(t *model.Tender) error {
+        if err := s.access.check(user, t.OrganizationID); err != nil {
+            return err
+        }
+        attachments, removed, err := detach(t.Attachments, attachmentID)
+        if err != nil {
+            return err
+        }
+        t.Attachments, a = attachments, removed
+        bumpTender(t, user.Username, s.clock.Now())
+        return nil
+    })
+    if err != nil {
+        return model.Tender{}, model.Attachment{}, err
+    }
+    return tender, a, nil
+}
+
+// TenderAttachments returns a page of the attachments of a tender visible
+// to username, oldest first.
+func (s *AttachmentService) TenderAttachments(tenderID, username string, p Page) ([]model.Attachment, string, error) {
+    tender, err := visibleTender(s.tenders, s.access, tenderID, username)
+    if err != nil {
+        return nil, "", err
+    }
+    return paginate(slices.Clone(tender.Attachments), p, attachmentKey, false)
+}
+
+// OpenTenderAttachment opens an attachment of a tender visible to username,
+// as of version ver of the tender, or its current state when ver is 0.
+func (s *AttachmentService) OpenTenderAttachment(tenderID, attachmentID, username string, ver int) (model.Attachment, io.ReadCloser, error) {
+    tender, err := visibleTender(s.tenders, s.access, tenderID, username)
+    if err != nil {
+        return model.Attachment{}, nil, err
+    }
+    attachments := tender.Attachments
+    if ver != 0 && ver != tender.Version {
+        versions, err := s.versions.TenderVersions(tenderID)
+        if err != nil {
+            return model.Attachment{}, nil, err
+        }
+        v, err := findVersion(versions, ver, func(v model.TenderVersion) int { return v.Version })
+        if err != nil {
+            return model.Attachment{}, nil, err
+        }
+        attachments = v.Attachments
+    }
+    return s.open(attachments, attachmentID)
+}
+
+// AttachToBid attaches a document to a bid that is not decided yet, on
+// behalf of its author.
+func (s *AttachmentService) AttachToBid(bidID, username string, ifMatch int, u Upload) (model.Bid, model.Attachment, error) {
+    user, err := s.access.employee(username)
+    if err != nil {
+        return model.Bid{}, model.Attachment{}, err
+    }
+    bid, err := getBid(s.bids, bidID)
+    if err != nil {
+        return model.Bid{}, model.Attachment{}, err
+    }
+    // Checked before the upload is stored; the author never changes.
+    if err := s.access.author(user, bid); err != nil {
+        return model.Bid{}, model.Attachment{}, err
+    }
+    a, err := s.save(u, user.Username)
+    if err != nil {
+        return model.Bid{}, model.Attachment{}, err
+    }
+    bid, err = s.audit.updateBid(s.bids, model.AuditBidAttach, user.Username, bidID, ifMatch, func(b *model.Bid) error {
+        if bidDecided(b.Status) {
+            return &StateError{Entity: "bid", Status: b.Status, Action: "attach documents to"}
+        }
+        attachments, err := attach(b.Attachments, a)
+        if err != nil {
+            return err
+        }
+        b.Attachments = attachments
+        bumpBid(b, user.Username, a.UploadedAt)
+        return nil
+    })
+    if err != nil {
+        return model.Bid{}, model.Attachment{}, err
+    }
+    bid, err = sealFor(s.tenders, s.access, user, bid, s.clock.Now())
+    if err != nil {
+        return model.Bid{}, model.Attachment{}, err
+    }
+    return bid, a, nil
+}
+
+// DetachFromBid removes an attachment from a bid that is not decided yet,
+// on behalf of its author. Past versions keep it.
+func (s *AttachmentService) DetachFromBid(bidID, attachmentID, username string, ifMatch int) (model.Bid, model.Attachment, error) {
+    user, err := s.access.employee(username)
+    if err != nil {
+        return model.Bid{}, model.Attachment{}, err
+    }
+    var a model.Attachment
+    bid, err := s.audit.updateBid(s.bids, model.AuditBidDetach, user.Username, bidID, ifMatch, func(b *model.Bid) error {
+        if err := s.access.author(user, *b); err != nil {
+            return err
+        }
+        if bidDecided(b.Status) {
+            return &StateError{Entity: "bid", Status: b.Status, Action: "detach documents from"}
+        }
+        attachments, removed, err := detach(b.Attachments, attachmentID)
+        if err != nil {
+            return err
+        }
+        b.Attachments, a = attachments, removed
+        bumpBid(b, user.Username, s.clock.Now())
+        return nil
+    })
+    if err != nil {
+        return model.Bid{}, model.Attachment{}, err
+    }
+    bid, err = sealFor(s.tenders, s.access, user, bid, s.clock.Now())
+    if err != nil {
+        return model.Bid{}, model.Attachment{}, err
+    }
+    return bid, a, nil
+}
+
+// BidAttachments returns a page of the attachments of a bid, oldest first,
+// to its author or a responsible of the tender's organization. Like the
+// rest of a bid's contents they are withheld from the latter while the bids
+// of its tender are sealed.
+func (s *AttachmentService) BidAttachments(bidID, username string, p Page) ([]model.Attachment, string, error) {
+    bid, err := readableBid(s.bids, s.tenders, s.access, bidID, username, s.clock.Now())
+    if err != nil {
+        return nil, "", err
+    }
+    return paginate(slices.Clone(bid.Attachments), p, attachmentKey, false)
+}
+
+// OpenBidAttachment opens an attachment of a bid as of version ver of the
+// bid, or its current state when ver is 0, for those BidAttachments lists
+// them to.
+func (s *AttachmentService) OpenBidAttachment(bidID, attachmentID string, ver int, username string) (model.Attachment, io.ReadCloser, error) {
+    bid, err := readableBid(s.bids, s.tenders, s.access, bidID, username, s.clock.Now())
+    if err != nil {
+        return model.Attachment{}, nil, err
+    }
+    attachments := bid.Attachments
+    if ver != 0 && ver != bid.Version {
+        versions, err := s.versions.BidVersions(bidID)
+        if err != nil {
+            return model.Attachment{}, nil, err
+        }
+        v, err := findVersion(versions, ver, func(v model.BidVersion) int { return v.Version })
+        if err != nil {
+            return model.Attachment{}, nil, err
+        }
+        attachments = v.Attachments
+    }
+    return s.open(attachments, attachmentID)
+}
+
+// save stores the content of u and describes it as an attachment uploaded
+// by actor.
+func (s *AttachmentService) save(u Upload, actor string) (model.Attachment, error) {
+    info, err := blob.Save(s.blobs, u.Body, s.MaxSize)
+    if errors.Is(err, blob.ErrTooLarge) {
+        return model.Attachment{}, fmt.Errorf("%w: at most %d bytes", ErrAttachmentTooLarge, s.MaxSize)
+    }
+    if err != nil {
+        return model.Attachment{}, err
+    }
+    return model.Attachment{
+        ID:          uuid.New().String(),
+        Name:        u.Name,
+        Size:        info.Size,
+        SHA256:      info.SHA256,
+        ContentType: u.ContentType,
+        UploadedBy:  actor,
+        UploadedAt:  s.clock.Now(),
+    }, nil
+}
+
+// open opens the content of the attachment with the given ID among
+// attachments.
+func (s *AttachmentService) open(attachments []model.Attachment, id string) (model.Attachment, io.ReadCloser, error) {
+    i := slices.IndexFunc(attachments, func(a model.Attachment) bool { return a.ID == id })
+    if i < 0 {
+        return model.Attachment{}, nil, &NotFoundError{Entity: "attachment", ID: id}
+    }
+    a := attachments[i]
+    r, err := s.blobs.Get(a.SHA256)
+    if err != nil {
+        return model.Attachment{}, nil, fmt.Errorf("open attachment %s: %w", id, err)
+    }
+    return a, r, nil
+}
+
+// attach returns a copy of attachments with a added, as the stored entity
+// shares the slice.
+func attach(attachments []model.Attachment, a model.Attachment) ([]model.Attachment, error) {
+    if len(attachments) >= MaxAttachments {
+        return nil, fmt.Errorf("%w: at most %d", ErrTooManyAttachments, MaxAttachments)
+    }
+    return append(slices.Clone(attachments), a), nil
+}
+
+// detach returns a copy of attachments without the one with the given ID,
+// and that attachment.
+func detach(attachments []model.Attachment, id string) ([]model.Attachment, model.Attachment, error) {
+    i := slices.IndexFunc(attachments, func(a model.Attachment) bool { return a.ID == id })
+    if i < 0 {
+        return nil, model.Attachment{}, &NotFoundError{Entity: "attachment", ID: id}
+    }
+    return slices.Delete(slices.Clone(attachments), i, i+1), attachments[i], nil
+}
+
+// attachmentKey orders attachments by upload time.
+func attachmentKey(a model.Attachment) sortKey {
+    return sortKey{Primary: timeKey(a.UploadedAt), ID: a.ID}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+    "errors"
+    "io"
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestBidAttachmentsRequireAuthor(t *testing.T) {
+    f := newFixture(t)
+    tender := f.tender(Deadlines{}, false)
+    bid := f.bid(tender.ID, nil)
+    upload := func() Upload {
+        return Upload{Name: "offer.txt", ContentType: "text/plain", Body: strings.NewReader("offer")}
+    }
+
+    _, _, err := f.attachments.AttachToBid(bid.ID, "", AnyVersion, upload())
+    wantErr(t, err, ErrUnauthorized)
+    _, _, err = f.attachments.AttachToBid(bid.ID, "alice", AnyVersion, upload())
+    wantErr(t, err, ErrForbidden)
+
+    got, a, err := f.attachments.AttachToBid(bid.ID, "dave", AnyVersion, upload())
+    f.must(err)
+    if len(got.Attachments) != 1 || got.Attachments[0].ID != a.ID {
+        t.Fatalf("attachments %v, want %s", got.Attachments, a.ID)
+    }
+
+    _, _, err = f.attachments.DetachFromBid(bid.ID, a.ID, "", AnyVersion)
+    wantErr(t, err, ErrUnauthorized)
+    _, _, err = f.attachments.DetachFromBid(bid.ID, a.ID, "erin", AnyVersion)
+    wantErr(t, err, ErrForbidden)
+    got, _, err = f.attachments.DetachFromBid(bid.ID, a.ID, "dave", AnyVersion)
+    f.must(err)
+    if len(got.Attachments) != 0 {
+        t.Fatalf("attachments %v left after detaching", got.Attachments)
+    }
+}
+
+func TestBidAttachmentsReadAccess(t *testing.T) {
+    f := newFixture(t)
+    tender := f.tender(Deadlines{Submission: f.at(time.Hour)}, true)
+    bid := f.bid(tender.ID, nil)
+    _, a, err := f.attachments.AttachToBid(bid.ID, "dave", AnyVersion, Upload{Name: "offer.txt", Body: strings.NewReader("offer")})
+    f.must(err)
+
+    read := func(user string, want error) {
+        t.Helper()
+        list, _, err := f.attachments.BidAttachments(bid.ID, user, Page{Limit: 10})
+        if !errors.Is(err, want) {
+            t.Errorf("attachments as %q: err = %v, want %v", user, err, want)
+        } else if err == nil && (len(list) != 1 || list[0].ID != a.ID) {
+            t.Errorf("attachments as %q = %v, want %s", user, list, a.ID)
+        }
+        _, content, err := f.attachments.OpenBidAttachment(bid.ID, a.ID, 0, user)
+        if !errors.Is(err, want) {
+            t.Errorf("download as %q: err = %v, want %v", user, err, want)
+        }
+        if err == nil {
+            defer content.Close()
+            if b, err := io.ReadAll(content); err != nil || string(b) != "offer" {
+                t.Errorf("download as %q = %q, %v", user, b, err)
+            }
+        }
+    }
+    read("", ErrUnauthorized)
+    read("nobody", ErrUnauthorized)
+    read("erin", ErrForbidden)
+    read("dave", nil)
+    read("alice", ErrSealed)
+
+    f.clock.advance(time.Hour)
+    read("erin", ErrForbidden)
+    read("alice", nil)
+}
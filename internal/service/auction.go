@@ -0,0 +1,247 @@
+package service
+
+import (
+    "cmp"
+    "errors"
+    "fmt"
+    "slices"
+    "time"
+
+    "tender/internal/model"
+)
+
+// Errors of reverse auctions.
+var (
+    // ErrInvalidAuction is returned for auction settings that cannot work.
+    ErrInvalidAuction = errors.New("invalid auction")
+    // ErrNotAuction is returned for auction actions on an ordinary tender.
+    ErrNotAuction = errors.New("tender is not an auction")
+    // ErrAuctionNotStarted is returned for prices offered before the auction
+    // starts.
+    ErrAuctionNotStarted = errors.New("auction has not started yet")
+    // ErrPriceRequired is returned for a bid without a price on an auction.
+    ErrPriceRequired = errors.New("bids in an auction need a price")
+)
+
+// UndercutError reports a price that does not beat the best price of an
+// auction by at least its step.
+type UndercutError struct {
+    Best model.Money
+    Step model.Money
+}
+
+func (e *UndercutError) Error() string {
+    limit := model.Money{Amount: e.Best.Amount - e.Step.Amount, Currency: e.Best.Currency}
+    return fmt.Sprintf("price must be at most %s, %s below the best price of %s", limit, e.Step, e.Best)
+}
+
+// newAuction checks the settings of an auction created at now on a tender
+// with the given budget. An auction without a start opens right away.
+func newAuction(a model.Auction, budget *model.Money, now time.Time) (*model.Auction, error) {
+    if a.Step.Amount <= 0 {
+        return nil, fmt.Errorf("%w: step must be positive", ErrInvalidAuction)
+    }
+    if budget != nil && a.Step.Currency != budget.Currency {
+        return nil, fmt.Errorf("%w: step must be in the currency of the budget", ErrInvalidAuction)
+    }
+    if a.ExtensionSeconds < 0 {
+        return nil, fmt.Errorf("%w: extension must not be negative", ErrInvalidAuction)
+    }
+    if a.StartsAt.IsZero() {
+        a.StartsAt = now
+    }
+    a.StartsAt = a.StartsAt.UTC()
+    a.EndsAt = a.EndsAt.UTC()
+    if !a.EndsAt.After(now) || !a.EndsAt.After(a.StartsAt) {
+        return nil, fmt.Errorf("%w: must end in the future and after it starts", ErrInvalidAuction)
+    }
+    a.BestPrice = nil
+    a.BestBidID = ""
+    return &a, nil
+}
+
+// claimPrice makes price, offered by bid bidID, the best price of the
+// auction on tenderID. It fails unless the auction is running and price,
+// in the currency of the step, undercuts the best price by the step. A
+// price offered within the extension period before the end moves the end,
+// and the decision deadline with it.
+//
+// The tender is the point of serialization: of two prices offered at once
+// only one can claim the best price, and the other is checked against it.
+func (s *BidService) claimPrice(tenderID, bidID string, price model.Money, actor string) error {
+    _, err := s.audit.updateTender(s.tenders, model.AuditTenderAuction, actor, tenderID, AnyVersion, func(t *model.Tender) error {
+        if t.Auction == nil {
+            return ErrNotAuction
+        }
+        if t.Status != model.TenderPublished {
+            return &StateError{Entity: "tender", Status: t.Status, Action: "bid in the auction of"}
+        }
+        now := s.clock.Now()
+        if !t.Auction.Started(now) {
+            return ErrAuctionNotStarted
+        }
+        if err := checkSubmission(*t, now, "bid in the auction"); err != nil {
+            return err
+        }
+        // The stored tender shares the auction; change a copy.
+        a := *t.Auction
+        if price.Currency != a.Step.Currency {
+            return fmt.Errorf("%w: the auction is in %s", ErrCurrencyMismatch, a.Step.Currency)
+        }
+        if a.BestPrice != nil && price.Amount > a.BestPrice.Amount-a.Step.Amount {
+            return &UndercutError{Best: *a.BestPrice, Step: a.Step}
+        }
+        a.BestPrice = &price
+        a.BestBidID = bidID
+        if end := now.Add(a.Extension()); end.After(a.EndsAt) {
+            if t.DecisionDeadline != nil {
+                decision := t.DecisionDeadline.Add(end.Sub(a.EndsAt))
+                t.DecisionDeadline = &decision
+            }
+            a.EndsAt = end
+            t.SubmissionDeadline = &end
+        }
+        t.Auction = &a
+        bumpTender(t, actor, now)
+        return nil
+    })
+    return err
+}
+
+// LowerPrice offers a new price for a bid in an auction on behalf of its
+// author. It must fit the budget of the tender and undercut the best price
+// of the auction by its step. ifMatch is the version of the bid the caller
+// expects, or AnyVersion.
+func (s *BidService) LowerPrice(id string, price model.Money, username string, ifMatch int) (model.Bid, error) {
+    user, err := s.access.employee(username)
+    if err != nil {
+        return model.Bid{}, err
+    }
+    bid, err := getBid(s.repo, id)
+    if err != nil {
+        return model.Bid{}, err
+    }
+    if err := s.access.author(user, bid); err != nil {
+        return model.Bid{}, err
+    }
+    // Checked up front so that a stale request claims nothing.
+    if ifMatch != AnyVersion && bid.Version != ifMatch {
+        return model.Bid{}, ErrPreconditionFailed
+    }
+    if !bidRunning(bid.Status) {
+        return model.Bid{}, &StateError{Entity: "bid", Status: bid.Status, Action: "lower the price of"}
+    }
+    tender, err := getTender(s.tenders, bid.TenderID)
+    if err != nil {
+        return model.Bid{}, err
+    }
+    if err := checkBudget(tender, price); err != nil {
+        return model.Bid{}, err
+    }
+    if err := s.claimPrice(bid.TenderID, bid.ID, price, user.Username); err != nil {
+        return model.Bid{}, err
+    }
+    updated, err := s.audit.updateBid(s.repo, model.AuditBidPrice, user.Username, id, AnyVersion, func(b *model.Bid) error {
+        if !bidRunning(b.Status) {
+            return &StateError{Entity: "bid", Status: b.Status, Action: "lower the price of"}
+        }
+        // A concurrent request for the same bid may have claimed an even
+        // lower price in the meantime.
+        if b.Price != nil && b.Price.Amount <= price.Amount {
+            return errUnchanged
+        }
+        b.Price = &price
+        bumpBid(b, user.Username, s.clock.Now())
+        return nil
+    })
+    if err != nil {
+        // The bid left the auction or could not be saved; the price it
+        // claimed must not stay the one to beat.
+        return model.Bid{}, errors.Join(err, s.releasePrice(bid.TenderID, bid.ID, user.Username))
+    }
+    return s.view(user, updated)
+}
+
+// releasePrice gives up the best price of the auction on tenderID if bid
+// bidID holds it, because the bid left the auction or its price was never
+// saved. The best price falls back to the lowest saved price among the bids
+// still in the running, the earliest on a tie, or to none.
+func (s *BidService) releasePrice(tenderID, bidID, actor string) error {
+    _, err := s.audit.updateTender(s.tenders, model.AuditTenderAuction, actor, tenderID, AnyVersion, func(t *model.Tender) error {
+        if t.Auction == nil || t.Auction.BestBidID != bidID {
+            return errUnchanged
+        }
+        bids, err := s.repo.BidsByTender(tenderID)
+        if err != nil {
+            return err
+        }
+        var best *model.Bid
+        for i, b := range bids {
+            if b.Price == nil || !bidRunning(b.Status) {
+                continue
+            }
+            if best == nil || b.Price.Amount < best.Price.Amount ||
+                b.Price.Amount == best.Price.Amount && b.UpdatedAt.Before(best.UpdatedAt) {
+                best = &bids[i]
+            }
+        }
+        // The stored tender shares the auction; change a copy.
+        a := *t.Auction
+        a.BestPrice, a.BestBidID = nil, ""
+        if best != nil {
+            price := *best.Price
+            a.BestPrice, a.BestBidID = &price, best.ID
+        }
+        t.Auction = &a
+        bumpTender(t, actor, s.clock.Now())
+        return nil
+    })
+    return err
+}
+
+// Leaderboard returns a page of the standings of the auction on tenderID,
+// best price first. Only bids still in the running are ranked. Auctions of
+// unpublished tenders are only visible to responsibles of the owning
+// organization.
+func (s *BidService) Leaderboard(tenderID, username string, p Page) ([]model.Standing, string, error) {
+    tender, err := getTender(s.tenders, tenderID)
+    if err != nil {
+        return nil, "", err
+    }
+    if tender.Status != model.TenderPublished {
+        if _, err := s.access.responsible(username, tender.OrganizationID); err != nil {
+            return nil, "", err
+        }
+    }
+    if tender.Auction == nil {
+        return nil, "", ErrNotAuction
+    }
+    bids, err := s.repo.BidsByTender(tenderID)
+    if err != nil {
+        return nil, "", err
+    }
+    bids = slices.DeleteFunc(bids, func(b model.Bid) bool { return b.Price == nil || !bidRunning(b.Status) })
+    slices.SortFunc(bids, func(a, b model.Bid) int {
+        if c := cmp.Compare(a.Price.Amount, b.Price.Amount); c != 0 {
+            return c
+        }
+        return a.UpdatedAt.Compare(b.UpdatedAt)
+    })
+    res := make([]model.Standing, len(bids))
+    for i, b := range bids {
+        res[i] = model.Standing{
+            Rank:       i + 1,
+            BidID:      b.ID,
+            AuthorType: b.AuthorType,
+            AuthorID:   b.AuthorID,
+            Price:      *b.Price,
+            UpdatedAt:  b.UpdatedAt,
+        }
+    }
+    return paginate(res, p, standingKey, false)
+}
+
+// standingKey orders standings by rank.
+func standingKey(st model.Standing) sortKey {
+    return sortKey{Primary: fmt.Sprintf("%010d", st.Rank), ID: st.BidID}
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+    "errors"
+    "testing"
+    "time"
+
+    "tender/internal/model"
+    "tender/internal/storage"
+)
+
+func rub(amount model.Amount) *model.Money {
+    return &model.Money{Amount: amount, Currency: "RUB"}
+}
+
+// auction creates an auction of orgA ending in an hour, with a step of 10,
+// and publishes it.
+func (f *fixture) auction() model.Tender {
+    f.t.Helper()
+    a := &model.Auction{EndsAt: *f.at(time.Hour), Step: *rub(10)}
+    t, err := f.tenders.Create("Auction", "d", "Delivery", orgA, "alice", nil, Deadlines{}, false, a, nil, nil)
+    f.must(err)
+    t, err = f.tenders.UpdateStatus(t.ID, model.TenderPublished, "alice", AnyVersion)
+    f.must(err)
+    return t
+}
+
+// wantBest fails the test unless the auction on tenderID has the best
+// price want, held by bidID.
+func (f *fixture) wantBest(tenderID string, want *model.Money, bidID string) {
+    f.t.Helper()
+    t, err := f.repo.GetTender(tenderID)
+    f.must(err)
+    got := t.Auction.BestPrice
+    if (got == nil) != (want == nil) || got != nil && *got != *want || t.Auction.BestBidID != bidID {
+        f.t.Fatalf("best price %v of %q, want %v of %q", got, t.Auction.BestBidID, want, bidID)
+    }
+}
+
+func TestLowerPriceRequiresAuthor(t *testing.T) {
+    f := newFixture(t)
+    tender := f.auction()
+    bid := f.bid(tender.ID, rub(1000))
+
+    _, err := f.bids.LowerPrice(bid.ID, *rub(900), "", AnyVersion)
+    wantErr(t, err, ErrUnauthorized)
+    _, err = f.bids.LowerPrice(bid.ID, *rub(900), "alice", AnyVersion)
+    wantErr(t, err, ErrForbidden)
+    f.wantBest(tender.ID, rub(1000), bid.ID)
+
+    got, err := f.bids.LowerPrice(bid.ID, *rub(900), "dave", AnyVersion)
+    f.must(err)
+    if *got.Price != *rub(900) {
+        t.Fatalf("price %v, want %v", got.Price, rub(900))
+    }
+    f.wantBest(tender.ID, rub(900), bid.ID)
+}
+
+func TestLeavingBidReleasesBestPrice(t *testing.T) {
+    f := newFixture(t)
+    tender := f.auction()
+    first := f.bid(tender.ID, rub(1000))
+    f.clock.advance(time.Minute)
+    second := f.bid(tender.ID, rub(1000-10))
+    f.clock.advance(time.Minute)
+    third := f.bid(tender.ID, rub(1000-20))
+
+    // Rejecting the best bid hands the best price to the next one.
+    _, err := f.bids.Decision(third.ID, model.DecisionRejected, "alice")
+    f.must(err)
+    f.wantBest(tender.ID, rub(990), second.ID)
+
+    // So does canceling it, and a canceled bid that is not the best changes
+    // nothing.
+    _, err = f.bids.UpdateStatus(second.ID, model.BidCanceled, "dave", AnyVersion)
+    f.must(err)
+    f.wantBest(tender.ID, rub(1000), first.ID)
+
+    // The released price is the one to beat from then on.
+    _, err = f.bids.Create("Late", "l", tender.ID, model.AuthorUser, employeeID("dave"), rub(995), nil)
+    var undercut *UndercutError
+    if !errors.As(err, &undercut) {
+        t.Fatalf("err = %v, want an UndercutError", err)
+    }
+    f.bid(tender.ID, rub(990))
+
+    _, err = f.bids.UpdateStatus(first.ID, model.BidCanceled, "dave", AnyVersion)
+    f.must(err)
+    tender, err = f.repo.GetTender(tender.ID)
+    f.must(err)
+    if tender.Auction.BestPrice == nil || *tender.Auction.BestPrice != *rub(990) {
+        t.Fatalf("best price %v after canceling a worse bid, want %v", tender.Auction.BestPrice, rub(990))
+    }
+}
+
+// failingBids is a bid store that fails to add bids.
+type failingBids struct {
+    storage.BidRepository
+}
+
+var errDiskFull = errors.New("disk full")
+
+func (failingBids) AddBid(model.Bid, model.AuditEvent) error {
+    return errDiskFull
+}
+
+func TestUnsavedBidReleasesClaim(t *testing.T) {
+    f := newFixture(t)
+    tender := f.auction()
+    bid := f.bid(tender.ID, rub(1000))
+
+    f.bids.repo = failingBids{f.repo}
+    _, err := f.bids.Create("Lost", "l", tender.ID, model.AuthorUser, employeeID("dave"), rub(500), nil)
+    wantErr(t, err, errDiskFull)
+    f.wantBest(tender.ID, rub(1000), bid.ID)
+}
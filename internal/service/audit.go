@@ -0,0 +1,184 @@
+package service
+
+import (
+    "fmt"
+    "time"
+
+    "tender/internal/model"
+    "tender/internal/storage"
+)
+
+// auditor makes the audit events of a service, which the repositories
+// store with the changes they record. Services hold one without a request
+// ID; their WithRequestID scopes a copy to a request.
+type auditor struct {
+    requestID string
+}
+
+// event returns e as made in the request of a.
+func (a auditor) event(e model.AuditEvent) model.AuditEvent {
+    e.RequestID = a.requestID
+    return e
+}
+
+// tender returns the event of action by actor on t, which it took from
+// version from to t.Version at t.UpdatedAt.
+func (a auditor) tender(action, actor string, from int, t model.Tender) model.AuditEvent {
+    return a.event(model.AuditEvent{
+        At:          t.UpdatedAt,
+        Actor:       actor,
+        Action:      action,
+        EntityType:  model.AuditTender,
+        EntityID:    t.ID,
+        TenderID:    t.ID,
+        FromVersion: from,
+        ToVersion:   t.Version,
+    })
+}
+
+// bid is tender for bids.
+func (a auditor) bid(action, actor string, from int, b model.Bid) model.AuditEvent {
+    return a.event(model.AuditEvent{
+        At:          b.UpdatedAt,
+        Actor:       actor,
+        Action:      action,
+        EntityType:  model.AuditBid,
+        EntityID:    b.ID,
+        TenderID:    b.TenderID,
+        FromVersion: from,
+        ToVersion:   b.Version,
+    })
+}
+
+// question returns the event of action by actor on q, which it took from
+// version from to q.Version at at.
+func (a auditor) question(action, actor string, from int, q model.Question, at time.Time) model.AuditEvent {
+    return a.event(model.AuditEvent{
+        At:          at,
+        Actor:       actor,
+        Action:      action,
+        EntityType:  model.AuditQuestion,
+        EntityID:    q.ID,
+        TenderID:    q.TenderID,
+        FromVersion: from,
+        ToVersion:   q.Version,
+    })
+}
+
+// updateTender is the package updateTender, storing the update together
+// with its event as action by actor.
+func (a auditor) updateTender(repo storage.TenderRepository, action, actor, id string, ifMatch int, fn func(*model.Tender) error) (model.Tender, error) {
+    return updateTender(repo, id, ifMatch, fn, func(from int, t model.Tender) model.AuditEvent {
+        return a.tender(action, actor, from, t)
+    })
+}
+
+// updateBid is updateTender for bids.
+func (a auditor) updateBid(repo storage.BidRepository, action, actor, id string, ifMatch int, fn func(*model.Bid) error) (model.Bid, error) {
+    return updateBid(repo, id, ifMatch, fn, func(from int, b model.Bid) model.AuditEvent {
+        return a.bid(action, actor, from, b)
+    })
+}
+
+// AuditQuery selects audit events. Every field that is set must match;
+// Since and Until bound the time of the events, Until exclusive.
+type AuditQuery struct {
+    Actor      string
+    Action     string
+    EntityType string
+    EntityID   string
+    TenderID   string
+    Since      time.Time
+    Until      time.Time
+}
+
+// AuditService reads the audit log.
+type AuditService struct {
+    repo    storage.AuditRepository
+    tenders storage.TenderRepository
+    access  access
+}
+
+func NewAuditService(r storage.AuditRepository, tenders storage.TenderRepository, users storage.UserRepository) *AuditService {
+    return &AuditService{repo: r, tenders: tenders, access: access{users: users}}
+}
+
+// List returns a page of the audit events matching q, newest first. Users
+// see the events of the tenders of the organizations they are responsible
+// for, and of the bids and questions on them; asking for the events of
+// another tender is forbidden.
+func (s *AuditService) List(username string, q AuditQuery, p Page) ([]model.AuditEvent, string, error) {
+    user, err := s.access.employee(username)
+    if err != nil {
+        return nil, "", err
+    }
+    var tenderIDs []string
+    if q.TenderID != "" {
+        tender, err := getTender(s.tenders, q.TenderID)
+        if err != nil {
+            return nil, "", err
+        }
+        if err := s.access.check(user, tender.OrganizationID); err != nil {
+            return nil, "", err
+        }
+        tenderIDs = []string{tender.ID}
+    } else {
+        orgs, err := s.access.organizations(user)
+        if err != nil {
+            return nil, "", err
+        }
+        if len(orgs) > 0 {
+            tenders, err := s.tenders.ListTenders(storage.TenderFilter{OrganizationIDs: orgs})
+            if err != nil {
+                return nil, "", err
+            }
+            for _, t := range tenders {
+                tenderIDs = append(tenderIDs, t.ID)
+            }
+        }
+    }
+    if len(tenderIDs) == 0 {
+        return paginate([]model.AuditEvent(nil), p, auditKey, true)
+    }
+    events, err := s.repo.ListAudit(storage.AuditFilter{
+        Actor:      q.Actor,
+        Action:     q.Action,
+        EntityType: q.EntityType,
+        EntityID:   q.EntityID,
+        TenderIDs:  tenderIDs,
+        Since:      q.Since,
+        Until:      q.Until,
+    })
+    if err != nil {
+        return nil, "", err
+    }
+    return paginate(events, p, auditKey, true)
+}
+
+// Verify checks the whole audit chain on behalf of a known employee: that
+// events are numbered from 1 without gaps, that each names the hash of the
+// one before it and that each hash matches its event. Removing events
+// from the end of the log keeps the chain valid; comparing Head with one
+// recorded earlier detects that.
+func (s *AuditService) Verify(username string) (model.AuditVerification, error) {
+    if _, err := s.access.employee(username); err != nil {
+        return model.AuditVerification{}, err
+    }
+    events, err := s.repo.ListAudit(storage.AuditFilter{})
+    if err != nil {
+        return model.AuditVerification{}, err
+    }
+    var prev model.AuditEvent
+    for i, e := range events {
+        if e.Seq != prev.Seq+1 || e.PrevHash != prev.Hash || e.Hash != e.ComputeHash() {
+            return model.AuditVerification{Events: int64(i), Head: prev.Hash, BrokenAt: prev.Seq + 1}, nil
+        }
+        prev = e
+    }
+    return model.AuditVerification{Valid: true, Events: int64(len(events)), Head: prev.Hash}, nil
+}
+
+// auditKey orders audit events by their position in the log.
+func auditKey(e model.AuditEvent) sortKey {
+    return sortKey{Primary: fmt.Sprintf("%020d", e.Seq)}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+    "os"
+    "path/filepath"
+    "reflect"
+    "testing"
+    "time"
+
+    "tender/internal/model"
+    "tender/internal/storage"
+)
+
+// events returns the audit events on entity id.
+func (f *fixture) events(id string) []model.AuditEvent {
+    f.t.Helper()
+    events, err := f.repo.ListAudit(storage.AuditFilter{EntityID: id})
+    f.must(err)
+    return events
+}
+
+// wantValid fails the test unless the audit chain of a is valid.
+func wantValid(t *testing.T, a *AuditService) {
+    t.Helper()
+    v, err := a.Verify("alice")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !v.Valid {
+        t.Fatalf("audit chain broken at %d", v.BrokenAt)
+    }
+}
+
+func TestAuditRecordsOnlyStoredChanges(t *testing.T) {
+    f := newFixture(t)
+    tender, err := f.tenders.Create("v1", "d", "Delivery", orgA, "alice", nil, Deadlines{}, false, nil, nil, nil)
+    f.must(err)
+    tender = f.rename(tender.ID, "alice", "v2")
+
+    // None of these changes is stored, so none is recorded.
+    name := "v3"
+    _, err = f.tenders.Edit(tender.ID, "alice", 1, &name, nil, nil, nil, Deadlines{}, nil)
+    wantErr(t, err, ErrPreconditionFailed)
+    stale := tender
+    stale.Name = name
+    stale.Version++
+    err = f.repo.UpdateTender(stale, 1, f.tenders.audit.tender(model.AuditTenderEdit, "alice", 1, stale))
+    wantErr(t, err, storage.ErrConflict)
+    f.tenders.repo = failingTenders{f.repo}
+    _, err = f.tenders.Edit(tender.ID, "alice", AnyVersion, &name, nil, nil, nil, Deadlines{}, nil)
+    wantErr(t, err, errDiskFull)
+
+    events := f.events(tender.ID)
+    if len(events) != 2 {
+        t.Fatalf("got %d events, want 2", len(events))
+    }
+    for i, e := range events {
+        if e.FromVersion != i || e.ToVersion != i+1 {
+            t.Errorf("event %d takes version %d to %d, want %d to %d", i, e.FromVersion, e.ToVersion, i, i+1)
+        }
+    }
+    wantValid(t, f.audit)
+}
+
+// reopen opens a copy of the file store at path as a crash would leave it,
+// with its changes and their events only in the log, less the last torn
+// bytes of the log.
+func reopen(t *testing.T, path string, torn int) *storage.Storage {
+    t.Helper()
+    dir := t.TempDir()
+    for _, suffix := range []string{"", ".wal"} {
+        data, err := os.ReadFile(path + suffix)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if suffix == ".wal" {
+            data = data[:len(data)-torn]
+        }
+        if err := os.WriteFile(filepath.Join(dir, "data.json"+suffix), data, 0o644); err != nil {
+            t.Fatal(err)
+        }
+    }
+    repo, err := storage.New(filepath.Join(dir, "data.json"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() { repo.Close() })
+    return repo
+}
+
+func TestAuditReplaysWithChanges(t *testing.T) {
+    f := newFixture(t)
+    tender := f.tender(Deadlines{Submission: f.at(time.Hour)}, false)
+    f.bid(tender.ID, nil)
+    q := f.ask(tender.ID)
+    _, err := f.questions.Answer(tender.ID, q.ID, "alice", "Soon", true, nil)
+    f.must(err)
+    want, err := f.repo.ListAudit(storage.AuditFilter{})
+    f.must(err)
+
+    repo := reopen(t, f.path, 0)
+    got, err := repo.ListAudit(storage.AuditFilter{})
+    f.must(err)
+    if !reflect.DeepEqual(got, want) {
+        t.Fatalf("replayed events\n %+v\nwant\n %+v", got, want)
+    }
+    wantValid(t, NewAuditService(repo, repo, repo))
+
+    // A crash in the middle of writing the answer loses it and its event
+    // alike.
+    repo = reopen(t, f.path, 10)
+    got, err = repo.ListAudit(storage.AuditFilter{})
+    f.must(err)
+    if !reflect.DeepEqual(got, want[:len(want)-1]) {
+        t.Fatalf("events after a torn answer\n %+v\nwant\n %+v", got, want[:len(want)-1])
+    }
+    answered, err := repo.GetQuestion(q.ID)
+    f.must(err)
+    if answered.Version != 1 || answered.Answered() {
+        t.Fatalf("question %+v after a torn answer", answered)
+    }
+    wantValid(t, NewAuditService(repo, repo, repo))
+}
@@ -2,7 +2,8 @@ package service
 
 import (
     "errors"
-    "sort"
+    "fmt"
+    "slices"
     "time"
 
     "github.com/google/uuid"
@@ -12,157 +13,674 @@ import (
 )
 
 type BidService struct {
-    repo *storage.Storage
+    repo        storage.BidRepository
+    tenders     storage.TenderRepository
+    versions    storage.VersionRepository
+    decisions   storage.DecisionRepository
+    evaluations storage.EvaluationRepository
+    reviews     storage.ReviewRepository
+    audit       auditor
+    access      access
+    clock       Clock
 }
 
-func NewBidService(r *storage.Storage) *BidService {
-    return &BidService{repo: r}
+func NewBidService(r storage.BidRepository, tenders storage.TenderRepository, versions storage.VersionRepository, decisions storage.DecisionRepository, evaluations storage.EvaluationRepository, reviews storage.ReviewRepository, users storage.UserRepository) *BidService {
+    return &BidService{repo: r, tenders: tenders, versions: versions, decisions: decisions, evaluations: evaluations, reviews: reviews, access: access{users: users}, clock: SystemClock}
 }
 
-func (s *BidService) Create(name, desc, tenderID, authorType, authorID string) (model.Bid, error) {
+// WithRequestID returns a copy of s that records its changes in the audit
+// log as made by the request id.
+func (s *BidService) WithRequestID(id string) *BidService {
+    c := *s
+    c.audit.requestID = id
+    return &c
+}
+
+// Create adds a bid on a published tender. price is optional, except in an
+// auction, where it must undercut the best price so far; either way it must
+// fit the budget of the tender. A bid on a tender with lots is for the open
+// lots lotIDs, and its price must fit their budgets as well. The author
+// must be an existing employee or organization.
+func (s *BidService) Create(name, desc, tenderID, authorType, authorID string, price *model.Money, lotIDs []string) (model.Bid, error) {
+    if err := s.access.known(authorType, authorID); err != nil {
+        return model.Bid{}, err
+    }
+    tender, err := getTender(s.tenders, tenderID)
+    if err != nil {
+        return model.Bid{}, err
+    }
+    if tender.Status != model.TenderPublished {
+        return model.Bid{}, &StateError{Entity: "tender", Status: tender.Status, Action: "bid on"}
+    }
+    now := s.clock.Now()
+    if err := checkSubmission(tender, now, "bid on the tender"); err != nil {
+        return model.Bid{}, err
+    }
+    if price != nil {
+        if err := checkBudget(tender, *price); err != nil {
+            return model.Bid{}, err
+        }
+    }
+    if err := checkBidLots(tender, lotIDs, price); err != nil {
+        return model.Bid{}, err
+    }
+    id := uuid.New().String()
+    if tender.Auction != nil {
+        if price == nil {
+            return model.Bid{}, ErrPriceRequired
+        }
+        // The auction decides between bids offered at once, so the price
+        // is claimed first and released again if the bid is not saved.
+        if err := s.claimPrice(tender.ID, id, *price, ""); err != nil {
+            return model.Bid{}, err
+        }
+    }
     b := model.Bid{
-        ID:          uuid.New().String(),
+        ID:          id,
         Name:        name,
         Description: desc,
         TenderID:    tenderID,
         AuthorType:  authorType,
         AuthorID:    authorID,
-        Status:      "Created",
+        Status:      model.BidCreated,
+        Price:       price,
+        LotIDs:      lotIDs,
         Version:     1,
-        CreatedAt:   time.Now().UTC(),
+        CreatedAt:   now,
     }
-    if err := s.repo.AddBid(b); err != nil {
+    b.UpdatedAt = b.CreatedAt
+    if err := s.repo.AddBid(b, s.audit.bid(model.AuditBidCreate, "", 0, b)); err != nil {
+        if tender.Auction != nil {
+            err = errors.Join(err, s.releasePrice(tender.ID, id, ""))
+        }
         return model.Bid{}, err
     }
     return b, nil
 }
 
-func (s *BidService) UserBids(username string) []model.Bid {
-    res := make([]model.Bid, 0)
-    for _, b := range s.repo.Data.Bids {
-        if b.AuthorID == username {
-            res = append(res, b)
+func (s *BidService) Get(id string) (model.Bid, error) {
+    return getBid(s.repo, id)
+}
+
+// Orders of bid lists.
+const (
+    // BidsByName orders bids alphabetically by name.
+    BidsByName = "name"
+    // BidsByPrice orders bids by currency, then cheapest first; bids
+    // without a price come last.
+    BidsByPrice = "price"
+)
+
+// UserBids returns the bids authored by username in the given order.
+func (s *BidService) UserBids(username, order string, p Page) ([]model.Bid, string, error) {
+    user, err := s.access.employee(username)
+    if err != nil {
+        return nil, "", err
+    }
+    res, err := s.repo.BidsByAuthor(user.ID)
+    if err != nil {
+        return nil, "", err
+    }
+    return paginate(res, p, bidOrder(order), false)
+}
+
+// ListForTender returns a page of the bids on a tender in the given order,
+// only those for lotID when it is set. While the tender's bids are sealed
+// only their identity, author and status are shown, and they are ordered by
+// ID so that neither the order nor the cursor gives their names or prices
+// away; nor can they be listed by lot.
+func (s *BidService) ListForTender(tenderID, lotID, order string, p Page) ([]model.Bid, string, error) {
+    tender, err := getTender(s.tenders, tenderID)
+    if err != nil {
+        return nil, "", err
+    }
+    sealed := tender.BidsSealed(s.clock.Now())
+    if lotID != "" {
+        if sealed {
+            return nil, "", ErrSealed
+        }
+        if _, ok := tender.Lot(lotID); !ok {
+            return nil, "", &NotFoundError{Entity: "lot", ID: lotID}
+        }
+    }
+    res, err := s.repo.BidsByTender(tenderID)
+    if err != nil {
+        return nil, "", err
+    }
+    if lotID != "" {
+        res = slices.DeleteFunc(res, func(b model.Bid) bool { return !slices.Contains(b.LotIDs, lotID) })
+    }
+    if sealed {
+        for i := range res {
+            res[i] = res[i].Seal()
         }
     }
-    sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
-    return res
+    return paginate(res, p, bidOrder(order), false)
 }
 
-func (s *BidService) ListForTender(tenderID string) []model.Bid {
-    res := make([]model.Bid, 0)
-    for _, b := range s.repo.Data.Bids {
-        if b.TenderID == tenderID {
-            res = append(res, b)
+// UpdateStatus moves the bid along its lifecycle on behalf of its author.
+// ifMatch is the version the caller expects, or AnyVersion.
+func (s *BidService) UpdateStatus(id, status, username string, ifMatch int) (model.Bid, error) {
+    user, err := s.access.employee(username)
+    if err != nil {
+        return model.Bid{}, err
+    }
+    bid, err := s.audit.updateBid(s.repo, model.AuditBidStatus, user.Username, id, ifMatch, func(bid *model.Bid) error {
+        if err := s.access.author(user, *bid); err != nil {
+            return err
+        }
+        if err := checkTransition("bid", bidTransitions, bid.Status, status); err != nil {
+            return err
+        }
+        if status == model.BidPublished {
+            tender, err := getTender(s.tenders, bid.TenderID)
+            if err != nil {
+                return err
+            }
+            if tender.Status != model.TenderPublished {
+                return &StateError{Entity: "tender", Status: tender.Status, Action: "publish a bid on"}
+            }
+            if err := checkSubmission(tender, s.clock.Now(), "publish the bid"); err != nil {
+                return err
+            }
+        }
+        bid.Status = status
+        bumpBid(bid, user.Username, s.clock.Now())
+        return nil
+    })
+    if err != nil {
+        return model.Bid{}, err
+    }
+    // A canceled bid no longer holds the best price of an auction.
+    if !bidRunning(bid.Status) {
+        if err := s.releasePrice(bid.TenderID, bid.ID, user.Username); err != nil {
+            return model.Bid{}, err
         }
     }
-    sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
-    return res
+    return s.view(user, bid)
 }
 
-func (s *BidService) UpdateStatus(id, status string) (model.Bid, error) {
-    bid, ok := s.repo.GetBid(id)
-    if !ok {
-        return model.Bid{}, errors.New("not found")
+// Edit changes the name and description of a bid on behalf of its author.
+func (s *BidService) Edit(id, username string, ifMatch int, name, desc *string) (model.Bid, error) {
+    user, err := s.access.employee(username)
+    if err != nil {
+        return model.Bid{}, err
     }
-    bid.History = append(bid.History, model.BidVersion{
-        Name:        bid.Name,
-        Description: bid.Description,
-        Status:      bid.Status,
-        Decision:    bid.Decision,
-        Feedback:    bid.Feedback,
-        Version:     bid.Version,
-        CreatedAt:   bid.CreatedAt,
+    bid, err := s.audit.updateBid(s.repo, model.AuditBidEdit, user.Username, id, ifMatch, func(bid *model.Bid) error {
+        if err := s.access.author(user, *bid); err != nil {
+            return err
+        }
+        if bidDecided(bid.Status) {
+            return &StateError{Entity: "bid", Status: bid.Status, Action: "edit"}
+        }
+        if name != nil {
+            bid.Name = *name
+        }
+        if desc != nil {
+            bid.Description = *desc
+        }
+        bumpBid(bid, user.Username, s.clock.Now())
+        return nil
     })
-    bid.Status = status
-    bid.Version++
-    if err := s.repo.UpdateBid(bid); err != nil {
+    if err != nil {
         return model.Bid{}, err
     }
-    return bid, nil
+    return s.view(user, bid)
 }
 
-func (s *BidService) Edit(id string, name, desc *string) (model.Bid, error) {
-    bid, ok := s.repo.GetBid(id)
-    if !ok {
-        return model.Bid{}, errors.New("not found")
+// Decision records username's vote on a published bid of a published
+// tender. One rejection rejects the bid; it is approved once approvals reach
+// the quorum of min(3, organization responsibles). Approving a bid closes the
+// tender and rejects its remaining open bids. No votes are taken after the
+// tender's decision deadline.
+func (s *BidService) Decision(id, decision, username string) (model.Bid, error) {
+    if decision != model.DecisionApproved && decision != model.DecisionRejected {
+        return model.Bid{}, ErrInvalidDecision
+    }
+    user, err := s.access.employee(username)
+    if err != nil {
+        return model.Bid{}, err
+    }
+    bid, err := getBid(s.repo, id)
+    if err != nil {
+        return model.Bid{}, err
+    }
+    tender, err := s.tenderFor(bid, user)
+    if err != nil {
+        return model.Bid{}, err
+    }
+    if bid.Status != model.BidPublished {
+        return model.Bid{}, &StateError{Entity: "bid", Status: bid.Status, Action: "decide on"}
     }
-    bid.History = append(bid.History, model.BidVersion{
-        Name:        bid.Name,
-        Description: bid.Description,
-        Status:      bid.Status,
-        Decision:    bid.Decision,
-        Feedback:    bid.Feedback,
-        Version:     bid.Version,
-        CreatedAt:   bid.CreatedAt,
+    if tender.Status != model.TenderPublished {
+        return model.Bid{}, &StateError{Entity: "tender", Status: tender.Status, Action: "decide on a bid of"}
+    }
+    if len(tender.Lots) > 0 {
+        return model.Bid{}, ErrHasLots
+    }
+    now := s.clock.Now()
+    if tender.DecisionClosed(now) {
+        return model.Bid{}, &DeadlineError{Deadline: "decision", At: *tender.DecisionDeadline, Action: "decide on the bid"}
+    }
+
+    err = s.decisions.AddDecision(model.BidDecision{
+        ID:        uuid.New().String(),
+        BidID:     bid.ID,
+        UserID:    user.ID,
+        Username:  user.Username,
+        Decision:  decision,
+        CreatedAt: now,
+    }, s.audit.event(model.AuditEvent{
+        At:          now,
+        Actor:       user.Username,
+        Action:      model.AuditBidVote,
+        EntityType:  model.AuditBid,
+        EntityID:    bid.ID,
+        TenderID:    bid.TenderID,
+        FromVersion: bid.Version,
+        ToVersion:   bid.Version,
+    }))
+    if errors.Is(err, storage.ErrAlreadyExists) {
+        return model.Bid{}, ErrDuplicateVote
+    }
+    if err != nil {
+        return model.Bid{}, err
+    }
+    votes, err := s.decisions.ListDecisions(bid.ID)
+    if err != nil {
+        return model.Bid{}, err
+    }
+    responsibles, err := s.access.responsibles(tender.OrganizationID)
+    if err != nil {
+        return model.Bid{}, err
+    }
+
+    outcome := evaluate(votes, quorum(len(responsibles)))
+    if outcome == "" {
+        return s.view(user, bid)
+    }
+    // The bid is decided first, so that a bid canceled or changed in the
+    // meantime leaves the tender as it is.
+    bid, err = s.audit.updateBid(s.repo, model.AuditBidDecision, user.Username, bid.ID, AnyVersion, func(b *model.Bid) error {
+        if b.Status != model.BidPublished {
+            return &StateError{Entity: "bid", Status: b.Status, Action: "decide on"}
+        }
+        b.Status = outcome
+        b.Decision = outcome
+        bumpBid(b, user.Username, now)
+        return nil
     })
-    if name != nil {
-        bid.Name = *name
+    if err != nil {
+        return model.Bid{}, err
     }
-    if desc != nil {
-        bid.Description = *desc
+    if outcome == model.DecisionRejected {
+        if err := s.releasePrice(bid.TenderID, bid.ID, user.Username); err != nil {
+            return model.Bid{}, err
+        }
+        return s.view(user, bid)
     }
-    bid.Version++
-    if err := s.repo.UpdateBid(bid); err != nil {
+    // Closing the tender is the point of serialization: of two bids
+    // approved concurrently only one closes it, and the other approval is
+    // taken back.
+    _, err = s.audit.updateTender(s.tenders, model.AuditTenderClose, user.Username, tender.ID, AnyVersion, func(t *model.Tender) error {
+        if t.Status != model.TenderPublished {
+            return &StateError{Entity: "tender", Status: t.Status, Action: "decide on a bid of"}
+        }
+        t.Status = model.TenderClosed
+        bumpTender(t, user.Username, now)
+        return nil
+    })
+    if err != nil {
+        return model.Bid{}, errors.Join(err, s.undecide(bid, user, now))
+    }
+    if err := rejectOthers(s.repo, s.audit, bid.TenderID, bid.ID, user.Username, now); err != nil {
         return model.Bid{}, err
     }
-    return bid, nil
+    return s.view(user, bid)
 }
 
-func (s *BidService) Decision(id, decision string) (model.Bid, error) {
-    bid, ok := s.repo.GetBid(id)
-    if !ok {
-        return model.Bid{}, errors.New("not found")
+// undecide takes back the approval of bid after closing its tender failed,
+// unless the bid changed since. The votes stay, so the next one decides
+// again.
+func (s *BidService) undecide(bid model.Bid, user model.Employee, now time.Time) error {
+    _, err := s.audit.updateBid(s.repo, model.AuditBidUndecide, user.Username, bid.ID, AnyVersion, func(b *model.Bid) error {
+        if b.Version != bid.Version {
+            return errUnchanged
+        }
+        b.Status = model.BidPublished
+        b.Decision = ""
+        bumpBid(b, user.Username, now)
+        return nil
+    })
+    return err
+}
+
+// rejectOthers rejects, on behalf of actor at now, every undecided bid on
+// a tender but the winner's, recording the rejections with a.
+func rejectOthers(repo storage.BidRepository, a auditor, tenderID, winnerID, actor string, now time.Time) error {
+    bids, err := repo.BidsByTender(tenderID)
+    if err != nil {
+        return err
+    }
+    for _, b := range bids {
+        if b.ID == winnerID {
+            continue
+        }
+        _, err := a.updateBid(repo, model.AuditBidReject, actor, b.ID, AnyVersion, func(b *model.Bid) error {
+            if b.Status != model.BidCreated && b.Status != model.BidPublished {
+                return errUnchanged
+            }
+            b.Status = model.BidRejected
+            b.Decision = model.DecisionRejected
+            bumpBid(b, actor, now)
+            return nil
+        })
+        if err != nil {
+            return err
+        }
     }
-    bid.Decision = decision
-    if err := s.repo.UpdateBid(bid); err != nil {
+    return nil
+}
+
+// Feedback records a review on the bid from a responsible of the tender's
+// organization.
+func (s *BidService) Feedback(id, feedback, username string) (model.Bid, error) {
+    user, err := s.access.employee(username)
+    if err != nil {
         return model.Bid{}, err
     }
-    return bid, nil
+    bid, err := getBid(s.repo, id)
+    if err != nil {
+        return model.Bid{}, err
+    }
+    if _, err := s.tenderFor(bid, user); err != nil {
+        return model.Bid{}, err
+    }
+    now := s.clock.Now()
+    repo := reviewedBids{BidRepository: s.repo, reviews: s.reviews, review: model.BidReview{
+        ID:               uuid.New().String(),
+        BidID:            bid.ID,
+        TenderID:         bid.TenderID,
+        AuthorID:         bid.AuthorID,
+        ReviewerUsername: user.Username,
+        Description:      feedback,
+        CreatedAt:        now,
+    }}
+    // Feedback keeps the latest review on the bid itself; the full list is
+    // available through Reviews.
+    bid, err = s.audit.updateBid(repo, model.AuditBidFeedback, user.Username, bid.ID, AnyVersion, func(b *model.Bid) error {
+        b.Feedback = feedback
+        bumpBid(b, user.Username, now)
+        return nil
+    })
+    if err != nil {
+        return model.Bid{}, err
+    }
+    return s.view(user, bid)
+}
+
+// reviewedBids stores the bid updates of Feedback together with the review
+// they record.
+type reviewedBids struct {
+    storage.BidRepository
+    reviews storage.ReviewRepository
+    review  model.BidReview
+}
+
+func (r reviewedBids) UpdateBid(b model.Bid, expected int, e model.AuditEvent) error {
+    return r.reviews.AddReview(r.review, b, expected, e)
 }
 
-func (s *BidService) Feedback(id, feedback string) (model.Bid, error) {
-    bid, ok := s.repo.GetBid(id)
-    if !ok {
-        return model.Bid{}, errors.New("not found")
+// Rollback copies the content of version ver of a bid into a new version
+// on behalf of its author.
+func (s *BidService) Rollback(id string, ver int, username string, ifMatch int) (model.Bid, error) {
+    user, err := s.access.employee(username)
+    if err != nil {
+        return model.Bid{}, err
     }
-    bid.Feedback = feedback
-    if err := s.repo.UpdateBid(bid); err != nil {
+    bid, err := s.audit.updateBid(s.repo, model.AuditBidRollback, user.Username, id, ifMatch, func(bid *model.Bid) error {
+        if err := s.access.author(user, *bid); err != nil {
+            return err
+        }
+        if bidDecided(bid.Status) {
+            return &StateError{Entity: "bid", Status: bid.Status, Action: "roll back"}
+        }
+        versions, err := s.bidVersions(*bid)
+        if err != nil {
+            return err
+        }
+        snap, err := findVersion(versions, ver, func(v model.BidVersion) int { return v.Version })
+        if err != nil {
+            return err
+        }
+        bid.Name = snap.Name
+        bid.Description = snap.Description
+        bid.Attachments = snap.Attachments
+        // Status and decision are owned by the lifecycle and are not rolled
+        // back, nor is the price, which only an auction may change.
+        bumpBid(bid, user.Username, s.clock.Now())
+        return nil
+    })
+    if err != nil {
         return model.Bid{}, err
     }
-    return bid, nil
+    return s.view(user, bid)
 }
 
-func (s *BidService) Rollback(id string, ver int) (model.Bid, error) {
-    bid, ok := s.repo.GetBid(id)
-    if !ok {
-        return model.Bid{}, errors.New("not found")
+// Reviews lets a responsible of the tender's organization read the reviews
+// left on any bid of authorUsername, provided that author has bid on the
+// tender. Newest reviews come first.
+func (s *BidService) Reviews(tenderID, authorUsername, requesterUsername string, p Page) ([]model.BidReview, string, error) {
+    requester, err := s.access.employee(requesterUsername)
+    if err != nil {
+        return nil, "", err
     }
-    if ver < 1 || ver > len(bid.History) {
-        return model.Bid{}, errors.New("version not found")
+    tender, err := getTender(s.tenders, tenderID)
+    if err != nil {
+        return nil, "", err
     }
-    snap := bid.History[ver-1]
-    bid.History = append(bid.History, model.BidVersion{
-        Name:        bid.Name,
-        Description: bid.Description,
-        Status:      bid.Status,
-        Decision:    bid.Decision,
-        Feedback:    bid.Feedback,
-        Version:     bid.Version,
-        CreatedAt:   bid.CreatedAt,
-    })
-    bid.Name = snap.Name
-    bid.Description = snap.Description
-    bid.Status = snap.Status
-    bid.Decision = snap.Decision
-    bid.Feedback = snap.Feedback
-    bid.Version++
-    if err := s.repo.UpdateBid(bid); err != nil {
+    if err := s.access.check(requester, tender.OrganizationID); err != nil {
+        return nil, "", err
+    }
+    author, err := s.access.users.GetEmployee(authorUsername)
+    if err != nil {
+        return nil, "", notFound(err, "author", authorUsername)
+    }
+    bids, err := s.repo.BidsByTender(tenderID)
+    if err != nil {
+        return nil, "", err
+    }
+    if !slices.ContainsFunc(bids, func(b model.Bid) bool { return b.AuthorID == author.ID }) {
+        return nil, "", &NotFoundError{Entity: "reviews for author", ID: authorUsername}
+    }
+    res, err := s.reviews.ReviewsByAuthor(author.ID)
+    if err != nil {
+        return nil, "", err
+    }
+    return paginate(res, p, reviewKey, true)
+}
+
+// Versions returns a page of every version of a bid readable by username,
+// oldest first; the last one is the current state.
+func (s *BidService) Versions(id, username string, p Page) ([]model.BidVersion, string, error) {
+    bid, err := s.readable(id, username)
+    if err != nil {
+        return nil, "", err
+    }
+    versions, err := s.bidVersions(bid)
+    if err != nil {
+        return nil, "", err
+    }
+    return paginate(versions, p, func(v model.BidVersion) sortKey { return versionKey(bid.ID, v.Version) }, false)
+}
+
+// Version returns version ver of a bid readable by username.
+func (s *BidService) Version(id string, ver int, username string) (model.BidVersion, error) {
+    bid, err := s.readable(id, username)
+    if err != nil {
+        return model.BidVersion{}, err
+    }
+    versions, err := s.bidVersions(bid)
+    if err != nil {
+        return model.BidVersion{}, err
+    }
+    return findVersion(versions, ver, func(v model.BidVersion) int { return v.Version })
+}
+
+// Diff lists the fields that differ between versions from and to of a bid
+// readable by username.
+func (s *BidService) Diff(id string, from, to int, username string) (model.VersionDiff, error) {
+    bid, err := s.readable(id, username)
+    if err != nil {
+        return model.VersionDiff{}, err
+    }
+    versions, err := s.bidVersions(bid)
+    if err != nil {
+        return model.VersionDiff{}, err
+    }
+    var revs []revision
+    for _, v := range versions {
+        revs = append(revs, bidRevision(v))
+    }
+    return diff(bidFields, revs, from, to)
+}
+
+// tenderFor returns the bid's tender after checking that user is
+// responsible for the organization that owns it and may see the bid.
+func (s *BidService) tenderFor(bid model.Bid, user model.Employee) (model.Tender, error) {
+    tender, err := getTender(s.tenders, bid.TenderID)
+    if err != nil {
+        return model.Tender{}, err
+    }
+    if err := s.access.check(user, tender.OrganizationID); err != nil {
+        return model.Tender{}, err
+    }
+    if tender.BidsSealed(s.clock.Now()) {
+        return model.Tender{}, ErrSealed
+    }
+    return tender, nil
+}
+
+// readable loads a bid whose past versions username is about to read.
+func (s *BidService) readable(id, username string) (model.Bid, error) {
+    return readableBid(s.repo, s.tenders, s.access, id, username, s.clock.Now())
+}
+
+// view returns b, just changed by user, as user may see it; see sealFor.
+func (s *BidService) view(user model.Employee, b model.Bid) (model.Bid, error) {
+    return sealFor(s.tenders, s.access, user, b, s.clock.Now())
+}
+
+// sealFor returns b as user may see it at now: sealed while the bids of its
+// tender are, unless user may act for its author. Most changes of a bid
+// already take its author or fail while it is sealed; their responses are
+// filtered all the same, so that the rule does not rest on those checks.
+// Only Create answers unfiltered, with what the caller just sent.
+func sealFor(tenders storage.TenderRepository, a access, user model.Employee, b model.Bid, now time.Time) (model.Bid, error) {
+    tender, err := getTender(tenders, b.TenderID)
+    if err != nil {
+        return model.Bid{}, err
+    }
+    if !tender.BidsSealed(now) {
+        return b, nil
+    }
+    switch err := a.author(user, b); {
+    case err == nil:
+        return b, nil
+    case errors.Is(err, ErrForbidden):
+        return b.Seal(), nil
+    default:
+        return model.Bid{}, err
+    }
+}
+
+// readableBid loads bid id for username to read its contents beyond what
+// sealFor shows: its past versions and attachments. Only those who may act
+// for its author, or responsibles of the tender's organization, may read
+// them, and the latter not while the bids of the tender are sealed at now.
+func readableBid(bids storage.BidRepository, tenders storage.TenderRepository, a access, id, username string, now time.Time) (model.Bid, error) {
+    user, err := a.employee(username)
+    if err != nil {
+        return model.Bid{}, err
+    }
+    bid, err := getBid(bids, id)
+    if err != nil {
+        return model.Bid{}, err
+    }
+    switch err := a.author(user, bid); {
+    case err == nil:
+        return bid, nil
+    case !errors.Is(err, ErrForbidden):
+        return model.Bid{}, err
+    }
+    tender, err := getTender(tenders, bid.TenderID)
+    if err != nil {
         return model.Bid{}, err
     }
+    if err := a.check(user, tender.OrganizationID); err != nil {
+        return model.Bid{}, err
+    }
+    if tender.BidsSealed(now) {
+        return model.Bid{}, ErrSealed
+    }
     return bid, nil
 }
 
-func (s *BidService) Reviews(tenderID, author string) []model.BidReview {
-    return s.repo.ListReviews(tenderID, author)
+// getBid loads a bid, reporting a missing one as NotFoundError.
+func getBid(repo storage.BidRepository, id string) (model.Bid, error) {
+    b, err := repo.GetBid(id)
+    return b, notFound(err, "bid", id)
+}
+
+// bidVersions returns every retained version of b, oldest first, ending
+// with its current state.
+func (s *BidService) bidVersions(b model.Bid) ([]model.BidVersion, error) {
+    res, err := s.versions.BidVersions(b.ID)
+    if err != nil {
+        return nil, err
+    }
+    return append(res, b.Snapshot()), nil
+}
+
+// WithHistory returns bs with their archived versions filled in. Sealed
+// bids are left without.
+func (s *BidService) WithHistory(bs ...model.Bid) ([]model.Bid, error) {
+    for i := range bs {
+        if bs[i].Sealed {
+            continue
+        }
+        h, err := s.versions.BidVersions(bs[i].ID)
+        if err != nil {
+            return nil, err
+        }
+        bs[i].History = h
+    }
+    return bs, nil
+}
+
+// bidKey orders bids alphabetically by name.
+func bidKey(b model.Bid) sortKey {
+    return sortKey{Primary: b.Name, ID: b.ID}
+}
+
+// bidPriceKey orders bids by currency and price, those without a price
+// last.
+func bidPriceKey(b model.Bid) sortKey {
+    if b.Price == nil {
+        return sortKey{Primary: "~", ID: b.ID}
+    }
+    return sortKey{Primary: fmt.Sprintf("%s %020d", b.Price.Currency, b.Price.Amount), ID: b.ID}
 }
 
+// bidOrder returns the sort key of the named order.
+func bidOrder(order string) func(model.Bid) sortKey {
+    if order == BidsByPrice {
+        return bidPriceKey
+    }
+    return bidKey
+}
+
+// reviewKey orders reviews by creation time; Reviews lists them newest first.
+func reviewKey(r model.BidReview) sortKey {
+    return sortKey{Primary: timeKey(r.CreatedAt), ID: r.ID}
+}
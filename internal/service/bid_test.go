@@ -0,0 +1,260 @@
+package service
+
+import (
+    "errors"
+    "testing"
+    "time"
+
+    "tender/internal/model"
+    "tender/internal/storage"
+)
+
+func TestBidRollbackAfterRollback(t *testing.T) {
+    f := newFixture(t)
+    tender := f.tender(Deadlines{}, false)
+    bid := f.bid(tender.ID, nil)
+    for _, name := range []string{"v3", "v4"} {
+        _, err := f.bids.Edit(bid.ID, "dave", AnyVersion, &name, nil)
+        f.must(err)
+    }
+
+    for i, to := range []int{1, 5, 3} {
+        got, err := f.bids.Rollback(bid.ID, to, "dave", AnyVersion)
+        f.must(err)
+        if want := 5 + i; got.Version != want {
+            t.Fatalf("rollback to %d: version %d, want %d", to, got.Version, want)
+        }
+    }
+    // Bids are created anonymously; every later version is dave's.
+    want := []string{"Offer", "Offer", "v3", "v4", "Offer", "Offer", "v3"}
+    for i, name := range want {
+        v, err := f.bids.Version(bid.ID, i+1, "dave")
+        f.must(err)
+        by := "dave"
+        if i == 0 {
+            by = ""
+        }
+        if v.Version != i+1 || v.Name != name || v.UpdatedBy != by {
+            t.Errorf("version %d = %d %q by %q, want %q by %q", i+1, v.Version, v.Name, v.UpdatedBy, name, by)
+        }
+    }
+}
+
+func TestSealFor(t *testing.T) {
+    f := newFixture(t)
+    tender := f.tender(Deadlines{Submission: f.at(time.Hour)}, true)
+    byUser := f.bid(tender.ID, nil)
+    byOrg, err := f.bids.Create("Offer", "o", tender.ID, model.AuthorOrganization, orgB, nil, nil)
+    f.must(err)
+
+    cases := []struct {
+        bid    model.Bid
+        viewer string
+        sealed bool
+    }{
+        {byUser, "", true},
+        {byUser, "alice", true},
+        {byUser, "dave", false},
+        {byOrg, "erin", true},
+        {byOrg, "dave", false},
+    }
+    for _, c := range cases {
+        var user model.Employee
+        if c.viewer != "" {
+            user, err = f.repo.GetEmployee(c.viewer)
+            f.must(err)
+        }
+        got, err := sealFor(f.repo, f.bids.access, user, c.bid, f.clock.Now())
+        f.must(err)
+        if got.Sealed != c.sealed || c.sealed && got.Name != "" {
+            t.Errorf("bid by %s as %q: sealed %v name %q, want sealed %v", c.bid.AuthorType, c.viewer, got.Sealed, got.Name, c.sealed)
+        }
+    }
+
+    // Past the submission deadline the bids are open to everyone.
+    f.clock.advance(time.Hour)
+    got, err := sealFor(f.repo, f.bids.access, model.Employee{}, byUser, f.clock.Now())
+    f.must(err)
+    if got.Sealed || got.Name != byUser.Name {
+        t.Errorf("bid still sealed after the deadline: %+v", got)
+    }
+}
+
+func TestBidVersionsAccess(t *testing.T) {
+    f := newFixture(t)
+    tender := f.tender(Deadlines{Submission: f.at(time.Hour)}, true)
+    byUser := f.bid(tender.ID, nil)
+    byOrg, err := f.bids.Create("Offer", "o", tender.ID, model.AuthorOrganization, orgB, nil, nil)
+    f.must(err)
+
+    read := func(bid model.Bid, user string, want error) {
+        t.Helper()
+        _, _, err := f.bids.Versions(bid.ID, user, Page{})
+        if !errors.Is(err, want) {
+            t.Errorf("versions of the bid by %s as %q: err = %v, want %v", bid.AuthorType, user, err, want)
+        }
+        _, err = f.bids.Version(bid.ID, 1, user)
+        if !errors.Is(err, want) {
+            t.Errorf("version 1 of the bid by %s as %q: err = %v, want %v", bid.AuthorType, user, err, want)
+        }
+        _, err = f.bids.Diff(bid.ID, 1, bid.Version, user)
+        if !errors.Is(err, want) {
+            t.Errorf("diff of the bid by %s as %q: err = %v, want %v", bid.AuthorType, user, err, want)
+        }
+    }
+    for _, bid := range []model.Bid{byUser, byOrg} {
+        read(bid, "", ErrUnauthorized)
+        read(bid, "nobody", ErrUnauthorized)
+        read(bid, "erin", ErrForbidden)
+        read(bid, "dave", nil)
+        // The tender's responsibles wait for the bids to be unsealed.
+        read(bid, "alice", ErrSealed)
+    }
+
+    f.clock.advance(time.Hour)
+    for _, bid := range []model.Bid{byUser, byOrg} {
+        read(bid, "erin", ErrForbidden)
+        read(bid, "alice", nil)
+    }
+}
+
+// racingReviews is a review store in which the bid is edited just before
+// every review is stored with its update.
+type racingReviews struct {
+    storage.ReviewRepository
+    bids storage.BidRepository
+}
+
+func (r racingReviews) AddReview(review model.BidReview, b model.Bid, expected int, e model.AuditEvent) error {
+    other, err := r.bids.GetBid(b.ID)
+    if err != nil {
+        return err
+    }
+    other.Version++
+    if err := r.bids.UpdateBid(other, expected, model.AuditEvent{Action: model.AuditBidEdit, EntityID: b.ID}); err != nil {
+        return err
+    }
+    return r.ReviewRepository.AddReview(review, b, expected, e)
+}
+
+func TestFeedbackStoresReviewWithBid(t *testing.T) {
+    f := newFixture(t)
+    tender := f.tender(Deadlines{}, false)
+    bid := f.bid(tender.ID, nil)
+
+    got, err := f.bids.Feedback(bid.ID, "fine", "alice")
+    f.must(err)
+    if got.Feedback != "fine" || got.Version != bid.Version+1 {
+        t.Fatalf("bid feedback %q at version %d", got.Feedback, got.Version)
+    }
+
+    // A feedback whose bid update never lands leaves no review behind.
+    f.bids.reviews = racingReviews{ReviewRepository: f.repo, bids: f.repo}
+    _, err = f.bids.Feedback(bid.ID, "late", "alice")
+    wantErr(t, err, ErrConflict)
+    reviews, _, err := f.bids.Reviews(tender.ID, "dave", "alice", Page{Limit: 50})
+    f.must(err)
+    if len(reviews) != 1 || reviews[0].Description != "fine" {
+        t.Fatalf("reviews %+v, want only %q", reviews, "fine")
+    }
+    events, err := f.repo.ListAudit(storage.AuditFilter{EntityID: bid.ID, Action: model.AuditBidFeedback})
+    f.must(err)
+    if len(events) != 1 {
+        t.Fatalf("got %d feedback events, want 1", len(events))
+    }
+}
+
+// cancelingBids is a bid store in which the author cancels a bid just
+// before its first update.
+type cancelingBids struct {
+    storage.BidRepository
+    canceled bool
+}
+
+func (c *cancelingBids) UpdateBid(b model.Bid, expected int, e model.AuditEvent) error {
+    if !c.canceled {
+        c.canceled = true
+        other, err := c.GetBid(b.ID)
+        if err != nil {
+            return err
+        }
+        other.Status = model.BidCanceled
+        other.Version++
+        if err := c.BidRepository.UpdateBid(other, expected, model.AuditEvent{Action: model.AuditBidStatus, EntityID: b.ID}); err != nil {
+            return err
+        }
+    }
+    return c.BidRepository.UpdateBid(b, expected, e)
+}
+
+// approve votes for bid as alice and bob, the quorum of orgA, with the
+// last vote run by f.bids as it is then.
+func (f *fixture) approve(bidID string, bids *BidService) (model.Bid, error) {
+    f.t.Helper()
+    _, err := f.bids.Decision(bidID, model.DecisionApproved, "alice")
+    f.must(err)
+    return bids.Decision(bidID, model.DecisionApproved, "bob")
+}
+
+// wantTenderStatus fails the test unless tender id has status.
+func (f *fixture) wantTenderStatus(id, status string) {
+    f.t.Helper()
+    t, err := f.repo.GetTender(id)
+    f.must(err)
+    if t.Status != status {
+        f.t.Fatalf("tender %s, want %s", t.Status, status)
+    }
+}
+
+func TestDecisionApprovesAndCloses(t *testing.T) {
+    f := newFixture(t)
+    tender := f.tender(Deadlines{}, false)
+    bid := f.bid(tender.ID, nil)
+    other := f.bid(tender.ID, nil)
+
+    got, err := f.approve(bid.ID, f.bids)
+    f.must(err)
+    if got.Status != model.BidApproved {
+        t.Fatalf("bid %s, want %s", got.Status, model.BidApproved)
+    }
+    f.wantTenderStatus(tender.ID, model.TenderClosed)
+    if status := f.bidStatus(other.ID); status != model.BidRejected {
+        t.Fatalf("other bid %s, want %s", status, model.BidRejected)
+    }
+}
+
+func TestDecisionOnCanceledBidKeepsTender(t *testing.T) {
+    f := newFixture(t)
+    tender := f.tender(Deadlines{}, false)
+    bid := f.bid(tender.ID, nil)
+    other := f.bid(tender.ID, nil)
+
+    bids := *f.bids
+    bids.repo = &cancelingBids{BidRepository: f.repo}
+    _, err := f.approve(bid.ID, &bids)
+    var stateErr *StateError
+    if !errors.As(err, &stateErr) || stateErr.Status != model.BidCanceled {
+        t.Fatalf("err = %v, want a StateError on a canceled bid", err)
+    }
+    f.wantTenderStatus(tender.ID, model.TenderPublished)
+    if status := f.bidStatus(other.ID); status != model.BidPublished {
+        t.Fatalf("other bid %s, want %s", status, model.BidPublished)
+    }
+}
+
+func TestFailedCloseTakesApprovalBack(t *testing.T) {
+    f := newFixture(t)
+    tender := f.tender(Deadlines{}, false)
+    bid := f.bid(tender.ID, nil)
+
+    bids := *f.bids
+    bids.tenders = failingTenders{f.repo}
+    _, err := f.approve(bid.ID, &bids)
+    wantErr(t, err, errDiskFull)
+    f.wantTenderStatus(tender.ID, model.TenderPublished)
+    got, err := f.repo.GetBid(bid.ID)
+    f.must(err)
+    if got.Status != model.BidPublished || got.Decision != "" || got.Version != bid.Version+2 {
+        t.Fatalf("bid %s, decision %q at version %d after a failed close", got.Status, got.Decision, got.Version)
+    }
+}
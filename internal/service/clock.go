@@ -0,0 +1,18 @@
+package service
+
+import "time"
+
+// Clock tells the services what time it is. Deadlines are checked and
+// enforced against it, so tests can move time instead of waiting for it.
+type Clock interface {
+    Now() time.Time
+}
+
+// SystemClock is the wall clock, in UTC.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+    return time.Now().UTC()
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+    "errors"
+
+    "tender/internal/model"
+    "tender/internal/storage"
+)
+
+// AnyVersion makes an update unconditional: it applies to whatever version
+// is current, as a request without If-Match does.
+const AnyVersion = 0
+
+// maxAttempts bounds how many times an update is re-applied after losing a
+// compare-and-swap race before it gives up with ErrConflict.
+const maxAttempts = 5
+
+// errUnchanged lets an update function report that the entity needs no
+// write; the update then returns the loaded entity as is.
+var errUnchanged = errors.New("unchanged")
+
+// updateTender applies fn to the current tender and stores the result with
+// a compare-and-swap on its version. ifMatch, unless AnyVersion, is the
+// version the caller last saw; any other version fails with
+// ErrPreconditionFailed. When a concurrent writer wins the race the tender
+// is reloaded and fn applied again, so fn must only mutate its argument.
+// event makes the audit event stored with the update from the version fn
+// was applied to and the result.
+func updateTender(repo storage.TenderRepository, id string, ifMatch int, fn func(*model.Tender) error, event func(from int, t model.Tender) model.AuditEvent) (model.Tender, error) {
+    for range maxAttempts {
+        t, err := getTender(repo, id)
+        if err != nil {
+            return model.Tender{}, err
+        }
+        if ifMatch != AnyVersion && t.Version != ifMatch {
+            return model.Tender{}, ErrPreconditionFailed
+        }
+        expected := t.Version
+        if err := fn(&t); errors.Is(err, errUnchanged) {
+            return t, nil
+        } else if err != nil {
+            return model.Tender{}, err
+        }
+        err = repo.UpdateTender(t, expected, event(expected, t))
+        if errors.Is(err, storage.ErrConflict) {
+            continue
+        }
+        if err != nil {
+            return model.Tender{}, notFound(err, "tender", id)
+        }
+        return t, nil
+    }
+    return model.Tender{}, ErrConflict
+}
+
+// updateBid is updateTender for bids.
+func updateBid(repo storage.BidRepository, id string, ifMatch int, fn func(*model.Bid) error, event func(from int, b model.Bid) model.AuditEvent) (model.Bid, error) {
+    for range maxAttempts {
+        b, err := getBid(repo, id)
+        if err != nil {
+            return model.Bid{}, err
+        }
+        if ifMatch != AnyVersion && b.Version != ifMatch {
+            return model.Bid{}, ErrPreconditionFailed
+        }
+        expected := b.Version
+        if err := fn(&b); errors.Is(err, errUnchanged) {
+            return b, nil
+        } else if err != nil {
+            return model.Bid{}, err
+        }
+        err = repo.UpdateBid(b, expected, event(expected, b))
+        if errors.Is(err, storage.ErrConflict) {
+            continue
+        }
+        if err != nil {
+            return model.Bid{}, notFound(err, "bid", id)
+        }
+        return b, nil
+    }
+    return model.Bid{}, ErrConflict
+}
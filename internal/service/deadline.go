@@ -0,0 +1,64 @@
+package service
+
+import (
+    "errors"
+    "fmt"
+    "time"
+
+    "tender/internal/model"
+)
+
+// ErrInvalidDeadline is returned for a deadline that is already past or a
+// decision deadline that does not follow the submission deadline.
+var ErrInvalidDeadline = errors.New("invalid deadline")
+
+// Deadlines are the deadlines of a tender. When editing, a nil field leaves
+// the current deadline unchanged.
+type Deadlines struct {
+    Submission *time.Time
+    Decision   *time.Time
+}
+
+// DeadlineError reports an action attempted after the tender deadline that
+// ends it.
+type DeadlineError struct {
+    Deadline string
+    At       time.Time
+    Action   string
+}
+
+func (e *DeadlineError) Error() string {
+    return fmt.Sprintf("cannot %s: %s deadline passed at %s", e.Action, e.Deadline, e.At.Format(time.RFC3339))
+}
+
+// setDeadlines applies the deadlines set in d to t. Newly set deadlines
+// must lie after now, and the decision deadline after the submission one.
+func setDeadlines(t *model.Tender, d Deadlines, now time.Time) error {
+    if d.Submission != nil {
+        if !d.Submission.After(now) {
+            return fmt.Errorf("%w: submission deadline must be in the future", ErrInvalidDeadline)
+        }
+        at := d.Submission.UTC()
+        t.SubmissionDeadline = &at
+    }
+    if d.Decision != nil {
+        if !d.Decision.After(now) {
+            return fmt.Errorf("%w: decision deadline must be in the future", ErrInvalidDeadline)
+        }
+        at := d.Decision.UTC()
+        t.DecisionDeadline = &at
+    }
+    if t.SubmissionDeadline != nil && t.DecisionDeadline != nil && !t.DecisionDeadline.After(*t.SubmissionDeadline) {
+        return fmt.Errorf("%w: decision deadline must be after the submission deadline", ErrInvalidDeadline)
+    }
+    return nil
+}
+
+// checkSubmission returns a DeadlineError when bids on t are no longer
+// accepted at now.
+func checkSubmission(t model.Tender, now time.Time, action string) error {
+    if t.SubmissionClosed(now) {
+        return &DeadlineError{Deadline: "submission", At: *t.SubmissionDeadline, Action: action}
+    }
+    return nil
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+    "errors"
+    "testing"
+    "time"
+
+    "tender/internal/model"
+)
+
+// wantDeadline fails the test unless err is a DeadlineError for deadline.
+func wantDeadline(t *testing.T, err error, deadline string) {
+    t.Helper()
+    var de *DeadlineError
+    if !errors.As(err, &de) || de.Deadline != deadline {
+        t.Fatalf("err = %v, want the %s deadline to have passed", err, deadline)
+    }
+}
+
+func TestCreateBidAfterSubmissionDeadline(t *testing.T) {
+    f := newFixture(t)
+    tender := f.tender(Deadlines{Submission: f.at(time.Hour)}, false)
+    f.clock.advance(time.Hour - time.Second)
+    f.bid(tender.ID, nil)
+
+    f.clock.advance(time.Second)
+    _, err := f.bids.Create("Late", "l", tender.ID, model.AuthorUser, employeeID("dave"), nil, nil)
+    wantDeadline(t, err, "submission")
+}
+
+func TestPublishBidAfterSubmissionDeadline(t *testing.T) {
+    f := newFixture(t)
+    tender := f.tender(Deadlines{Submission: f.at(time.Hour)}, false)
+    bid, err := f.bids.Create("Offer", "o", tender.ID, model.AuthorUser, employeeID("dave"), nil, nil)
+    f.must(err)
+    f.clock.advance(2 * time.Hour)
+    _, err = f.bids.UpdateStatus(bid.ID, model.BidPublished, "dave", AnyVersion)
+    wantDeadline(t, err, "submission")
+}
+
+func TestDecisionDeadline(t *testing.T) {
+    f := newFixture(t)
+    tender := f.tender(Deadlines{Submission: f.at(time.Hour), Decision: f.at(2 * time.Hour)}, false)
+    first := f.bid(tender.ID, nil)
+    second := f.bid(tender.ID, nil)
+
+    // Votes are taken between the deadlines, but not after the decision
+    // deadline.
+    f.clock.advance(time.Hour)
+    got, err := f.bids.Decision(first.ID, model.DecisionRejected, "alice")
+    f.must(err)
+    if got.Status != model.BidRejected {
+        t.Fatalf("status %s, want %s", got.Status, model.BidRejected)
+    }
+    f.clock.advance(time.Hour)
+    _, err = f.bids.Decision(second.ID, model.DecisionApproved, "alice")
+    wantDeadline(t, err, "decision")
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+    "errors"
+    "fmt"
+
+    "tender/internal/storage"
+)
+
+// Sentinel errors returned by the services. Handlers translate them into
+// HTTP status codes; see handler.writeError.
+var (
+    // ErrNotFound means the requested tender, bid, version or review does
+    // not exist. NotFoundError matches it with errors.Is.
+    ErrNotFound = errors.New("not found")
+    // ErrUnauthorized means the acting username is unknown.
+    ErrUnauthorized = errors.New("user does not exist or is invalid")
+    // ErrForbidden means the user exists but may not perform the action.
+    ErrForbidden = errors.New("not enough rights to perform the action")
+
+    // ErrInvalidStatus is returned for a status outside the lifecycle enum.
+    ErrInvalidStatus = errors.New("invalid status")
+    // ErrInvalidDecision is returned for a decision other than Approved or Rejected.
+    ErrInvalidDecision = errors.New("invalid decision")
+    // ErrDuplicateVote is returned when a responsible votes on a bid twice.
+    ErrDuplicateVote = errors.New("decision already submitted by this user")
+    // ErrInvalidPage is returned for out-of-range limits and offsets or a
+    // malformed cursor.
+    ErrInvalidPage = errors.New("invalid pagination parameters")
+
+    // ErrSealed means the contents of a bid are asked for while the bids
+    // of its tender are sealed.
+    ErrSealed = errors.New("bids are sealed until the tender is opened or its submission deadline passes")
+    // ErrNotSealed is returned for opening a tender that is not sealed.
+    ErrNotSealed = errors.New("tender is not sealed")
+
+    // ErrPreconditionFailed means the If-Match version sent by the client
+    // is not the current version of the entity.
+    ErrPreconditionFailed = errors.New("entity was modified: version does not match")
+    // ErrConflict means a write kept losing races with concurrent writers
+    // and was abandoned.
+    ErrConflict = errors.New("entity is being modified concurrently, retry later")
+)
+
+// NotFoundError names the missing entity.
+type NotFoundError struct {
+    Entity string
+    ID     string
+}
+
+func (e *NotFoundError) Error() string {
+    return fmt.Sprintf("%s %q not found", e.Entity, e.ID)
+}
+
+func (e *NotFoundError) Is(target error) bool {
+    return target == ErrNotFound
+}
+
+// notFound turns storage.ErrNotFound into a NotFoundError for entity and
+// passes any other error through.
+func notFound(err error, entity, id string) error {
+    if errors.Is(err, storage.ErrNotFound) {
+        return &NotFoundError{Entity: entity, ID: id}
+    }
+    return err
+}
@@ -0,0 +1,290 @@
+package service
+
+import (
+    "cmp"
+    "errors"
+    "fmt"
+    "math"
+    "slices"
+
+    "github.com/google/uuid"
+
+    "tender/internal/model"
+)
+
+// Errors of bid evaluation.
+var (
+    // ErrInvalidCriteria is returned for evaluation criteria that cannot
+    // work together.
+    ErrInvalidCriteria = errors.New("invalid evaluation criteria")
+    // ErrNoCriteria is returned for evaluating the bids of a tender that has
+    // no evaluation criteria.
+    ErrNoCriteria = errors.New("tender has no evaluation criteria")
+    // ErrInvalidScores is returned for scores that do not match the
+    // criteria of the tender.
+    ErrInvalidScores = errors.New("invalid scores")
+)
+
+// checkCriteria checks that criteria have unique names and at most one of
+// them is a price criterion. Price scores only compare prices in the
+// currency of the budget, so a price criterion needs one. The budget does
+// not fix the currency of bids already priced: see Ranking.
+func checkCriteria(criteria []model.Criterion, budget *model.Money) error {
+    seen := map[string]bool{}
+    prices := 0
+    for _, c := range criteria {
+        if seen[c.Name] {
+            return fmt.Errorf("%w: criterion %q is given twice", ErrInvalidCriteria, c.Name)
+        }
+        seen[c.Name] = true
+        if c.Kind == model.CriterionPrice {
+            prices++
+        }
+    }
+    if prices > 1 {
+        return fmt.Errorf("%w: at most one criterion may be the price", ErrInvalidCriteria)
+    }
+    if prices > 0 && budget == nil {
+        return fmt.Errorf("%w: a price criterion needs a budget", ErrInvalidCriteria)
+    }
+    return nil
+}
+
+// checkScores checks that scores score every Score criterion of criteria,
+// and nothing else, from 0 to model.MaxScore.
+func checkScores(criteria []model.Criterion, scores map[string]int) error {
+    n := 0
+    for _, c := range criteria {
+        if c.Kind != model.CriterionScore {
+            continue
+        }
+        score, ok := scores[c.Name]
+        if !ok {
+            return fmt.Errorf("%w: criterion %q is not scored", ErrInvalidScores, c.Name)
+        }
+        if score < 0 || score > model.MaxScore {
+            return fmt.Errorf("%w: %q must be scored from 0 to %d", ErrInvalidScores, c.Name, model.MaxScore)
+        }
+        n++
+    }
+    if n != len(scores) {
+        return fmt.Errorf("%w: only the criteria of kind %s are scored", ErrInvalidScores, model.CriterionScore)
+    }
+    return nil
+}
+
+// Evaluate records the scores a responsible of the tender's organization
+// gives a published bid, replacing the ones they gave it before. Like
+// decisions, evaluations end with the decision deadline.
+func (s *BidService) Evaluate(id, username string, scores map[string]int) (model.BidEvaluation, error) {
+    user, err := s.access.employee(username)
+    if err != nil {
+        return model.BidEvaluation{}, err
+    }
+    bid, err := getBid(s.repo, id)
+    if err != nil {
+        return model.BidEvaluation{}, err
+    }
+    tender, err := s.tenderFor(bid, user)
+    if err != nil {
+        return model.BidEvaluation{}, err
+    }
+    if len(tender.Criteria) == 0 {
+        return model.BidEvaluation{}, ErrNoCriteria
+    }
+    if bid.Status != model.BidPublished {
+        return model.BidEvaluation{}, &StateError{Entity: "bid", Status: bid.Status, Action: "evaluate"}
+    }
+    if tender.Status != model.TenderPublished {
+        return model.BidEvaluation{}, &StateError{Entity: "tender", Status: tender.Status, Action: "evaluate a bid of"}
+    }
+    now := s.clock.Now()
+    if tender.DecisionClosed(now) {
+        return model.BidEvaluation{}, &DeadlineError{Deadline: "decision", At: *tender.DecisionDeadline, Action: "evaluate the bid"}
+    }
+    if err := checkScores(tender.Criteria, scores); err != nil {
+        return model.BidEvaluation{}, err
+    }
+
+    e := model.BidEvaluation{
+        ID:                uuid.New().String(),
+        BidID:             bid.ID,
+        TenderID:          tender.ID,
+        EvaluatorID:       user.ID,
+        EvaluatorUsername: user.Username,
+        Scores:            scores,
+        CreatedAt:         now,
+        UpdatedAt:         now,
+    }
+    // The store keeps the ID and creation time of an earlier evaluation;
+    // report them as it will.
+    evaluations, err := s.evaluations.EvaluationsByTender(tender.ID)
+    if err != nil {
+        return model.BidEvaluation{}, err
+    }
+    if i := slices.IndexFunc(evaluations, func(o model.BidEvaluation) bool {
+        return o.BidID == bid.ID && o.EvaluatorID == user.ID
+    }); i >= 0 {
+        e.ID, e.CreatedAt = evaluations[i].ID, evaluations[i].CreatedAt
+    }
+    err = s.evaluations.PutEvaluation(e, s.audit.event(model.AuditEvent{
+        At:          now,
+        Actor:       user.Username,
+        Action:      model.AuditBidEvaluate,
+        EntityType:  model.AuditBid,
+        EntityID:    bid.ID,
+        TenderID:    tender.ID,
+        FromVersion: bid.Version,
+        ToVersion:   bid.Version,
+    }))
+    if err != nil {
+        return model.BidEvaluation{}, err
+    }
+    return e, nil
+}
+
+// Ranking returns a page of the bids on a tender ranked by their weighted
+// total score, best first, for a responsible of the owning organization.
+// Bids still being written or canceled are left out.
+//
+// A bid's score on a Score criterion is the mean of its evaluators' scores,
+// 0 while nobody scored it. On the price criterion the cheapest bid in the
+// currency of the tender's budget scores model.MaxScore and the others in
+// that currency proportionally less. Bids without a price, or priced in
+// another currency before the budget changed, score 0. The total is the
+// mean of a bid's scores weighted by criterion.
+// Scores on criteria the tender no longer has are ignored.
+func (s *BidService) Ranking(tenderID, username string, p Page) ([]model.BidRanking, string, error) {
+    user, err := s.access.employee(username)
+    if err != nil {
+        return nil, "", err
+    }
+    tender, err := getTender(s.tenders, tenderID)
+    if err != nil {
+        return nil, "", err
+    }
+    if err := s.access.check(user, tender.OrganizationID); err != nil {
+        return nil, "", err
+    }
+    if tender.BidsSealed(s.clock.Now()) {
+        return nil, "", ErrSealed
+    }
+    if len(tender.Criteria) == 0 {
+        return nil, "", ErrNoCriteria
+    }
+    bids, err := s.repo.BidsByTender(tenderID)
+    if err != nil {
+        return nil, "", err
+    }
+    bids = slices.DeleteFunc(bids, func(b model.Bid) bool {
+        return b.Status == model.BidCreated || b.Status == model.BidCanceled
+    })
+    evaluations, err := s.evaluations.EvaluationsByTender(tenderID)
+    if err != nil {
+        return nil, "", err
+    }
+    byBid := map[string][]model.BidEvaluation{}
+    for _, e := range evaluations {
+        byBid[e.BidID] = append(byBid[e.BidID], e)
+    }
+    var cheapest *model.Money
+    for _, b := range bids {
+        if inBudgetCurrency(tender, b) && (cheapest == nil || b.Price.Amount < cheapest.Amount) {
+            cheapest = b.Price
+        }
+    }
+
+    res := make([]model.BidRanking, len(bids))
+    for i, b := range bids {
+        res[i] = rankBid(tender.Criteria, b, byBid[b.ID], cheapest)
+    }
+    slices.SortFunc(res, func(a, b model.BidRanking) int {
+        if c := cmp.Compare(b.Total, a.Total); c != 0 {
+            return c
+        }
+        return cmp.Compare(a.BidID, b.BidID)
+    })
+    for i := range res {
+        res[i].Rank = i + 1
+    }
+    return paginate(res, p, rankingKey, false)
+}
+
+// inBudgetCurrency reports whether b has a price in the currency of the
+// budget of t, the only prices the price criterion compares.
+func inBudgetCurrency(t model.Tender, b model.Bid) bool {
+    return b.Price != nil && t.Budget != nil && b.Price.Currency == t.Budget.Currency
+}
+
+// rankBid scores bid b on criteria from its evaluations. cheapest is the
+// lowest price among the ranked bids in the budget's currency, nil when
+// none has one.
+func rankBid(criteria []model.Criterion, b model.Bid, evaluations []model.BidEvaluation, cheapest *model.Money) model.BidRanking {
+    var price float64
+    if b.Price != nil && cheapest != nil && b.Price.Currency == cheapest.Currency {
+        price = model.MaxScore * float64(cheapest.Amount) / float64(b.Price.Amount)
+    }
+    r := model.BidRanking{
+        BidID:       b.ID,
+        BidName:     b.Name,
+        AuthorType:  b.AuthorType,
+        AuthorID:    b.AuthorID,
+        Status:      b.Status,
+        Price:       b.Price,
+        Scores:      map[string]float64{},
+        Evaluations: []model.EvaluatorScores{},
+    }
+    for _, c := range criteria {
+        if c.Kind == model.CriterionPrice {
+            r.Scores[c.Name] = roundScore(price)
+            continue
+        }
+        sum := 0
+        for _, e := range evaluations {
+            sum += e.Scores[c.Name]
+        }
+        r.Scores[c.Name] = 0
+        if len(evaluations) > 0 {
+            r.Scores[c.Name] = roundScore(float64(sum) / float64(len(evaluations)))
+        }
+    }
+    r.Total = weightedScore(criteria, func(c model.Criterion) float64 { return r.Scores[c.Name] })
+    for _, e := range evaluations {
+        r.Evaluations = append(r.Evaluations, model.EvaluatorScores{
+            Username: e.EvaluatorUsername,
+            Scores:   e.Scores,
+            Total: weightedScore(criteria, func(c model.Criterion) float64 {
+                if c.Kind == model.CriterionPrice {
+                    return price
+                }
+                return float64(e.Scores[c.Name])
+            }),
+        })
+    }
+    slices.SortFunc(r.Evaluations, func(a, b model.EvaluatorScores) int { return cmp.Compare(a.Username, b.Username) })
+    return r
+}
+
+// weightedScore is the mean of the scores of criteria weighted by their
+// weights.
+func weightedScore(criteria []model.Criterion, score func(model.Criterion) float64) float64 {
+    var sum, weights float64
+    for _, c := range criteria {
+        sum += float64(c.Weight) * score(c)
+        weights += float64(c.Weight)
+    }
+    if weights == 0 {
+        return 0
+    }
+    return roundScore(sum / weights)
+}
+
+// roundScore rounds a score to two decimals.
+func roundScore(x float64) float64 {
+    return math.Round(x*100) / 100
+}
+
+// rankingKey orders rankings by rank.
+func rankingKey(r model.BidRanking) sortKey {
+    return sortKey{Primary: fmt.Sprintf("%010d", r.Rank), ID: r.BidID}
+}
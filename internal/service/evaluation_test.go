@@ -0,0 +1,65 @@
+package service
+
+import (
+    "testing"
+
+    "tender/internal/model"
+)
+
+// usd returns amount in US dollars.
+func usd(amount model.Amount) *model.Money {
+    return &model.Money{Amount: amount, Currency: "USD"}
+}
+
+var priceCriteria = []model.Criterion{{Name: "Price", Kind: model.CriterionPrice, Weight: 1}}
+
+// wantPriceScores fails the test unless the ranking of tenderID gives each
+// bid the price score in want.
+func (f *fixture) wantPriceScores(tenderID string, want map[string]float64) {
+    f.t.Helper()
+    ranking, _, err := f.bids.Ranking(tenderID, "alice", Page{Limit: 50})
+    f.must(err)
+    if len(ranking) != len(want) {
+        f.t.Fatalf("ranked %d bids, want %d", len(ranking), len(want))
+    }
+    for _, r := range ranking {
+        if got := r.Scores["Price"]; got != want[r.BidID] {
+            f.t.Errorf("bid priced %v scores %v on price, want %v", r.Price, got, want[r.BidID])
+        }
+    }
+}
+
+func TestRankingAfterBudgetCurrencyChange(t *testing.T) {
+    f := newFixture(t)
+    tender, err := f.tenders.Create("Priced", "d", "Delivery", orgA, "alice", rub(10000000), Deadlines{}, false, nil, priceCriteria, nil)
+    f.must(err)
+    _, err = f.tenders.UpdateStatus(tender.ID, model.TenderPublished, "alice", AnyVersion)
+    f.must(err)
+    cheap := f.bid(tender.ID, rub(5000000))
+    dear := f.bid(tender.ID, rub(10000000))
+
+    _, err = f.tenders.Edit(tender.ID, "alice", AnyVersion, nil, nil, nil, usd(1000000), Deadlines{}, nil)
+    f.must(err)
+    dollars := f.bid(tender.ID, usd(500000))
+    pricier := f.bid(tender.ID, usd(1000000))
+
+    // Roubles and dollars do not compare: only prices in the budget's
+    // currency are scored.
+    f.wantPriceScores(tender.ID, map[string]float64{
+        cheap.ID: 0, dear.ID: 0, dollars.ID: model.MaxScore, pricier.ID: model.MaxScore / 2,
+    })
+}
+
+func TestRankingWithPriceCriterionAddedLater(t *testing.T) {
+    f := newFixture(t)
+    tender := f.tender(Deadlines{}, false)
+    roubles := f.bid(tender.ID, rub(3000000))
+    dollars := f.bid(tender.ID, usd(1000000))
+
+    _, err := f.tenders.Edit(tender.ID, "alice", AnyVersion, nil, nil, nil, rub(10000000), Deadlines{}, priceCriteria)
+    f.must(err)
+
+    // The dollar price is lower in number but offered before the budget
+    // fixed the currency, so it is not the cheapest.
+    f.wantPriceScores(tender.ID, map[string]float64{roubles.ID: model.MaxScore, dollars.ID: 0})
+}
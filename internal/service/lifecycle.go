@@ -0,0 +1,68 @@
+package service
+
+import (
+    "fmt"
+
+    "tender/internal/model"
+)
+
+// TransitionError reports a status change the lifecycle does not allow.
+type TransitionError struct {
+    Entity string
+    From   string
+    To     string
+}
+
+func (e *TransitionError) Error() string {
+    return fmt.Sprintf("%s cannot move from %s to %s", e.Entity, e.From, e.To)
+}
+
+// tenderTransitions lists the statuses reachable from each tender status.
+var tenderTransitions = map[string][]string{
+    model.TenderCreated:   {model.TenderPublished, model.TenderClosed},
+    model.TenderPublished: {model.TenderClosed},
+    model.TenderClosed:    {},
+}
+
+// StateError reports an action that is not possible in the entity's
+// current status.
+type StateError struct {
+    Entity string
+    Status string
+    Action string
+}
+
+func (e *StateError) Error() string {
+    return fmt.Sprintf("cannot %s a %s in status %s", e.Action, e.Entity, e.Status)
+}
+
+// bidTransitions lists the statuses an author can move a bid to. Approved
+// and Rejected are only reachable through a decision, so they have no
+// outgoing edges and are not valid targets here.
+var bidTransitions = map[string][]string{
+    model.BidCreated:   {model.BidPublished, model.BidCanceled},
+    model.BidPublished: {model.BidCanceled},
+    model.BidCanceled:  {},
+}
+
+// bidDecided reports whether a bid has reached a final decision.
+func bidDecided(status string) bool {
+    return status == model.BidApproved || status == model.BidRejected
+}
+
+// bidRunning reports whether a bid is neither canceled nor decided.
+func bidRunning(status string) bool {
+    return status == model.BidCreated || status == model.BidPublished
+}
+
+func checkTransition(entity string, table map[string][]string, from, to string) error {
+    if _, ok := table[to]; !ok {
+        return fmt.Errorf("%w: %q", ErrInvalidStatus, to)
+    }
+    for _, s := range table[from] {
+        if s == to {
+            return nil
+        }
+    }
+    return &TransitionError{Entity: entity, From: from, To: to}
+}
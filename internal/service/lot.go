@@ -0,0 +1,359 @@
+package service
+
+import (
+    "errors"
+    "fmt"
+    "slices"
+    "time"
+
+    "github.com/google/uuid"
+
+    "tender/internal/model"
+    "tender/internal/storage"
+)
+
+// Errors of tender lots.
+var (
+    // ErrInvalidLots is returned for lots that cannot work together and
+    // for bids that do not name the lots of their tender properly.
+    ErrInvalidLots = errors.New("invalid lots")
+    // ErrHasLots is returned for a decision on a bid of a tender with lots,
+    // which are awarded one by one instead.
+    ErrHasLots = errors.New("tender has lots: award each lot instead")
+)
+
+// lotTransitions lists the statuses a responsible can move a lot to.
+// Awarded is only reachable through Award.
+var lotTransitions = map[string][]string{
+    model.LotOpen:     {model.LotCanceled},
+    model.LotCanceled: {},
+}
+
+// LotService manages the lots of tenders and their awards.
+type LotService struct {
+    tenders storage.TenderRepository
+    bids    storage.BidRepository
+    audit   auditor
+    access  access
+    clock   Clock
+}
+
+func NewLotService(tenders storage.TenderRepository, bids storage.BidRepository, users storage.UserRepository) *LotService {
+    return &LotService{tenders: tenders, bids: bids, access: access{users: users}, clock: SystemClock}
+}
+
+// WithRequestID returns a copy of s that records its changes in the audit
+// log as made by the request id.
+func (s *LotService) WithRequestID(id string) *LotService {
+    c := *s
+    c.audit.requestID = id
+    return &c
+}
+
+// newLot makes l an open lot of a tender, added by actor at now.
+func newLot(l model.Lot, actor string, now time.Time) model.Lot {
+    l.ID = uuid.New().String()
+    l.Status = model.LotOpen
+    l.AwardedBidID = ""
+    l.UpdatedBy = actor
+    l.UpdatedAt = now
+    return l
+}
+
+// checkLots checks the lots of t: an auction cannot have lots, and the
+// budgets of the lots must all be in one currency, that of the budget of
+// the tender if it has one.
+func checkLots(t model.Tender) error {
+    if len(t.Lots) == 0 {
+        return nil
+    }
+    if t.Auction != nil {
+        return fmt.Errorf("%w: an auction cannot have lots", ErrInvalidLots)
+    }
+    currency := ""
+    if t.Budget != nil {
+        currency = t.Budget.Currency
+    }
+    for _, l := range t.Lots {
+        if l.Budget == nil {
+            continue
+        }
+        if currency == "" {
+            currency = l.Budget.Currency
+        }
+        if l.Budget.Currency != currency {
+            return fmt.Errorf("%w: lot budgets must all be in %s", ErrCurrencyMismatch, currency)
+        }
+    }
+    return nil
+}
+
+// checkBidLots checks the lots a bid on t is for: distinct open lots of t,
+// at least one, when t has lots, and none otherwise. When each of them has
+// a budget, the price must not exceed their sum.
+func checkBidLots(t model.Tender, lotIDs []string, price *model.Money) error {
+    if len(t.Lots) == 0 {
+        if len(lotIDs) > 0 {
+            return fmt.Errorf("%w: the tender has no lots", ErrInvalidLots)
+        }
+        return nil
+    }
+    if len(lotIDs) == 0 {
+        return fmt.Errorf("%w: a bid on a tender with lots must name its lots", ErrInvalidLots)
+    }
+    var budget *model.Money
+    for i, id := range lotIDs {
+        if slices.Contains(lotIDs[:i], id) {
+            return fmt.Errorf("%w: lot %s is named twice", ErrInvalidLots, id)
+        }
+        l, ok := t.Lot(id)
+        if !ok {
+            return &NotFoundError{Entity: "lot", ID: id}
+        }
+        if l.Status != model.LotOpen {
+            return &StateError{Entity: "lot", Status: l.Status, Action: "bid on"}
+        }
+        switch {
+        case l.Budget == nil:
+            budget = nil
+        case i == 0:
+            budget = &model.Money{Amount: l.Budget.Amount, Currency: l.Budget.Currency}
+        case budget != nil:
+            budget.Amount += l.Budget.Amount
+        }
+    }
+    if price == nil || budget == nil {
+        return nil
+    }
+    return checkBudget(model.Tender{Budget: budget}, *price)
+}
+
+// List returns a page of the lots of a tender visible to username, in the
+// given statuses or all of them, ordered by name.
+func (s *LotService) List(tenderID, username string, statuses []string, p Page) ([]model.Lot, string, error) {
+    tender, err := visibleTender(s.tenders, s.access, tenderID, username)
+    if err != nil {
+        return nil, "", err
+    }
+    res := slices.DeleteFunc(slices.Clone(tender.Lots), func(l model.Lot) bool {
+        return len(statuses) > 0 && !slices.Contains(statuses, l.Status)
+    })
+    return paginate(res, p, lotKey, false)
+}
+
+// Get returns a lot of a tender visible to username, along with the
+// tender, whose version is the lot's.
+func (s *LotService) Get(tenderID, lotID, username string) (model.Tender, model.Lot, error) {
+    tender, err := visibleTender(s.tenders, s.access, tenderID, username)
+    if err != nil {
+        return model.Tender{}, model.Lot{}, err
+    }
+    l, ok := tender.Lot(lotID)
+    if !ok {
+        return model.Tender{}, model.Lot{}, &NotFoundError{Entity: "lot", ID: lotID}
+    }
+    return tender, l, nil
+}
+
+// Add adds a lot to a tender that is not published yet. ifMatch is the
+// version of the tender the caller expects, or AnyVersion.
+func (s *LotService) Add(tenderID, username string, ifMatch int, lot model.Lot) (model.Tender, model.Lot, error) {
+    user, err := s.access.employee(username)
+    if err != nil {
+        return model.Tender{}, model.Lot{}, err
+    }
+    lot = newLot(lot, user.Username, s.clock.Now())
+    tender, err := s.audit.updateTender(s.tenders, model.AuditLotAdd, user.Username, tenderID, ifMatch, func(t *model.Tender) error {
+        if err := s.access.check(user, t.OrganizationID); err != nil {
+            return err
+        }
+        if t.Status != model.TenderCreated {
+            return &StateError{Entity: "tender", Status: t.Status, Action: "add lots to"}
+        }
+        // The stored tender shares its lots; append to a copy.
+        t.Lots = append(slices.Clone(t.Lots), lot)
+        if err := checkLots(*t); err != nil {
+            return err
+        }
+        bumpTender(t, user.Username, lot.UpdatedAt)
+        return nil
+    })
+    if err != nil {
+        return model.Tender{}, model.Lot{}, err
+    }
+    return tender, lot, nil
+}
+
+// UpdateStatus moves a lot along its lifecycle, which only a responsible
+// can do. Canceling the last open lot of a published tender closes it,
+// rejecting the bids that won nothing.
+func (s *LotService) UpdateStatus(tenderID, lotID, status, username string, ifMatch int) (model.Tender, model.Lot, error) {
+    user, err := s.access.employee(username)
+    if err != nil {
+        return model.Tender{}, model.Lot{}, err
+    }
+    now := s.clock.Now()
+    return s.updateLot(model.AuditLotStatus, tenderID, lotID, ifMatch, user, "", now, func(t *model.Tender, l *model.Lot) error {
+        if t.Status == model.TenderClosed {
+            return &StateError{Entity: "tender", Status: t.Status, Action: "change the lots of"}
+        }
+        if err := checkTransition("lot", lotTransitions, l.Status, status); err != nil {
+            return err
+        }
+        l.Status = status
+        return nil
+    })
+}
+
+// Award awards a lot of a published tender to a published bid for it. The
+// bid is approved, if it was not already for another lot. Awarding the last
+// open lot closes the tender, rejecting the bids that won nothing.
+//
+// The bid is approved first, since an approved bid can no longer be
+// canceled: the lot cannot go to a bid that left in the meantime. Should
+// the award itself fail, the approval is taken back.
+func (s *LotService) Award(tenderID, lotID, bidID, username string, ifMatch int) (model.Tender, model.Lot, error) {
+    user, err := s.access.employee(username)
+    if err != nil {
+        return model.Tender{}, model.Lot{}, err
+    }
+    now := s.clock.Now()
+    awardable := func(t *model.Tender, l *model.Lot) error {
+        if t.Status != model.TenderPublished {
+            return &StateError{Entity: "tender", Status: t.Status, Action: "award a lot of"}
+        }
+        if t.BidsSealed(now) {
+            return ErrSealed
+        }
+        if t.DecisionClosed(now) {
+            return &DeadlineError{Deadline: "decision", At: *t.DecisionDeadline, Action: "award the lot"}
+        }
+        if l.Status != model.LotOpen {
+            return &StateError{Entity: "lot", Status: l.Status, Action: "award"}
+        }
+        return nil
+    }
+    // Checked up front too, so that a bid is only approved for an award
+    // that can be made.
+    tender, err := getTender(s.tenders, tenderID)
+    if err != nil {
+        return model.Tender{}, model.Lot{}, err
+    }
+    if err := s.access.check(user, tender.OrganizationID); err != nil {
+        return model.Tender{}, model.Lot{}, err
+    }
+    if ifMatch != AnyVersion && tender.Version != ifMatch {
+        return model.Tender{}, model.Lot{}, ErrPreconditionFailed
+    }
+    l, ok := tender.Lot(lotID)
+    if !ok {
+        return model.Tender{}, model.Lot{}, &NotFoundError{Entity: "lot", ID: lotID}
+    }
+    if err := awardable(&tender, &l); err != nil {
+        return model.Tender{}, model.Lot{}, err
+    }
+
+    var approved bool
+    bid, err := s.audit.updateBid(s.bids, model.AuditBidAward, user.Username, bidID, AnyVersion, func(b *model.Bid) error {
+        approved = false
+        if b.TenderID != tenderID || !slices.Contains(b.LotIDs, lotID) {
+            return fmt.Errorf("%w: bid %s is not for lot %s", ErrInvalidLots, bidID, lotID)
+        }
+        if b.Status == model.BidApproved {
+            return errUnchanged
+        }
+        if b.Status != model.BidPublished {
+            return &StateError{Entity: "bid", Status: b.Status, Action: "award a lot to"}
+        }
+        b.Status = model.BidApproved
+        b.Decision = model.DecisionApproved
+        bumpBid(b, user.Username, now)
+        approved = true
+        return nil
+    })
+    if err != nil {
+        return model.Tender{}, model.Lot{}, err
+    }
+    tender, lot, err := s.updateLot(model.AuditLotAward, tenderID, lotID, ifMatch, user, bidID, now, func(t *model.Tender, l *model.Lot) error {
+        if err := awardable(t, l); err != nil {
+            return err
+        }
+        l.Status = model.LotAwarded
+        l.AwardedBidID = bidID
+        return nil
+    })
+    if err != nil {
+        if approved {
+            err = errors.Join(err, s.unapprove(tenderID, bid, user, now))
+        }
+        return model.Tender{}, model.Lot{}, err
+    }
+    return tender, lot, nil
+}
+
+// unapprove takes back the approval of bid for an award that failed, unless
+// the bid changed since or has won another lot of the tender meanwhile.
+func (s *LotService) unapprove(tenderID string, bid model.Bid, user model.Employee, now time.Time) error {
+    _, err := s.audit.updateBid(s.bids, model.AuditBidUnaward, user.Username, bid.ID, AnyVersion, func(b *model.Bid) error {
+        if b.Version != bid.Version {
+            return errUnchanged
+        }
+        tender, err := getTender(s.tenders, tenderID)
+        if err != nil {
+            return err
+        }
+        if slices.ContainsFunc(tender.Lots, func(l model.Lot) bool { return l.AwardedBidID == bid.ID }) {
+            return errUnchanged
+        }
+        b.Status = model.BidPublished
+        b.Decision = ""
+        bumpBid(b, user.Username, now)
+        return nil
+    })
+    return err
+}
+
+// updateLot applies fn to a lot of a tender on behalf of user, a
+// responsible of its organization, recorded as action, and closes the
+// tender once a published tender has no open lot left. Closing rejects the
+// bids still undecided but winner.
+func (s *LotService) updateLot(action, tenderID, lotID string, ifMatch int, user model.Employee, winner string, now time.Time, fn func(*model.Tender, *model.Lot) error) (model.Tender, model.Lot, error) {
+    var lot model.Lot
+    tender, err := s.audit.updateTender(s.tenders, action, user.Username, tenderID, ifMatch, func(t *model.Tender) error {
+        if err := s.access.check(user, t.OrganizationID); err != nil {
+            return err
+        }
+        i := slices.IndexFunc(t.Lots, func(l model.Lot) bool { return l.ID == lotID })
+        if i < 0 {
+            return &NotFoundError{Entity: "lot", ID: lotID}
+        }
+        // The stored tender shares its lots; change a copy.
+        lots := slices.Clone(t.Lots)
+        if err := fn(t, &lots[i]); err != nil {
+            return err
+        }
+        lots[i].UpdatedBy = user.Username
+        lots[i].UpdatedAt = now
+        lot = lots[i]
+        t.Lots = lots
+        if t.Status == model.TenderPublished && t.LotsResolved() {
+            t.Status = model.TenderClosed
+        }
+        bumpTender(t, user.Username, now)
+        return nil
+    })
+    if err != nil {
+        return model.Tender{}, model.Lot{}, err
+    }
+    if tender.Status == model.TenderClosed {
+        if err := rejectOthers(s.bids, s.audit, tenderID, winner, user.Username, now); err != nil {
+            return model.Tender{}, model.Lot{}, err
+        }
+    }
+    return tender, lot, nil
+}
+
+// lotKey orders lots alphabetically by name.
+func lotKey(l model.Lot) sortKey {
+    return sortKey{Primary: l.Name, ID: l.ID}
+}
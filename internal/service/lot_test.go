@@ -0,0 +1,116 @@
+package service
+
+import (
+    "errors"
+    "testing"
+
+    "tender/internal/model"
+    "tender/internal/storage"
+)
+
+// lotTender creates a tender of orgA with two lots and publishes it.
+func (f *fixture) lotTender() model.Tender {
+    f.t.Helper()
+    lots := []model.Lot{{Name: "North", Quantity: 1}, {Name: "South", Quantity: 1}}
+    t, err := f.tenders.Create("Lots", "d", "Delivery", orgA, "alice", nil, Deadlines{}, false, nil, nil, lots)
+    f.must(err)
+    t, err = f.tenders.UpdateStatus(t.ID, model.TenderPublished, "alice", AnyVersion)
+    f.must(err)
+    return t
+}
+
+// lotBid creates a published bid of dave on lotIDs of tenderID.
+func (f *fixture) lotBid(tenderID string, lotIDs ...string) model.Bid {
+    f.t.Helper()
+    b, err := f.bids.Create("Offer", "o", tenderID, model.AuthorUser, employeeID("dave"), nil, lotIDs)
+    f.must(err)
+    b, err = f.bids.UpdateStatus(b.ID, model.BidPublished, "dave", AnyVersion)
+    f.must(err)
+    return b
+}
+
+func (f *fixture) bidStatus(id string) string {
+    f.t.Helper()
+    b, err := f.repo.GetBid(id)
+    f.must(err)
+    return b.Status
+}
+
+func TestAwardLots(t *testing.T) {
+    f := newFixture(t)
+    tender := f.lotTender()
+    north, south := tender.Lots[0].ID, tender.Lots[1].ID
+    both := f.lotBid(tender.ID, north, south)
+    loser := f.lotBid(tender.ID, south)
+
+    got, lot, err := f.lots.Award(tender.ID, north, both.ID, "alice", AnyVersion)
+    f.must(err)
+    if lot.Status != model.LotAwarded || lot.AwardedBidID != both.ID || got.Status != model.TenderPublished {
+        t.Fatalf("lot %s to %q, tender %s", lot.Status, lot.AwardedBidID, got.Status)
+    }
+    if f.bidStatus(both.ID) != model.BidApproved {
+        t.Fatal("awarded bid is not approved")
+    }
+    // The bid is already approved for the other lot.
+    got, _, err = f.lots.Award(tender.ID, south, both.ID, "alice", AnyVersion)
+    f.must(err)
+    if got.Status != model.TenderClosed || f.bidStatus(loser.ID) != model.BidRejected {
+        t.Fatalf("tender %s, losing bid %s after the last award", got.Status, f.bidStatus(loser.ID))
+    }
+}
+
+func TestAwardCanceledBid(t *testing.T) {
+    f := newFixture(t)
+    tender := f.lotTender()
+    north := tender.Lots[0].ID
+    bid := f.lotBid(tender.ID, north)
+    _, err := f.bids.UpdateStatus(bid.ID, model.BidCanceled, "dave", AnyVersion)
+    f.must(err)
+
+    _, _, err = f.lots.Award(tender.ID, north, bid.ID, "alice", AnyVersion)
+    var se *StateError
+    if !errors.As(err, &se) || se.Entity != "bid" {
+        t.Fatalf("err = %v, want a bid StateError", err)
+    }
+    tender, err = f.repo.GetTender(tender.ID)
+    f.must(err)
+    if tender.Lots[0].Status != model.LotOpen {
+        t.Fatalf("lot %s after awarding a canceled bid", tender.Lots[0].Status)
+    }
+}
+
+// failingTenders is a tender store that fails to update tenders.
+type failingTenders struct {
+    storage.TenderRepository
+}
+
+func (failingTenders) UpdateTender(model.Tender, int, model.AuditEvent) error {
+    return errDiskFull
+}
+
+func TestFailedAwardTakesApprovalBack(t *testing.T) {
+    f := newFixture(t)
+    tender := f.lotTender()
+    north := tender.Lots[0].ID
+    bid := f.lotBid(tender.ID, north)
+
+    f.lots.tenders = failingTenders{f.repo}
+    _, _, err := f.lots.Award(tender.ID, north, bid.ID, "alice", AnyVersion)
+    wantErr(t, err, errDiskFull)
+    got, err := f.repo.GetBid(bid.ID)
+    f.must(err)
+    if got.Status != model.BidPublished || got.Decision != "" {
+        t.Fatalf("bid %s, decision %q after a failed award", got.Status, got.Decision)
+    }
+    // Approving and taking it back are both versions of the bid.
+    if got.Version != bid.Version+2 {
+        t.Fatalf("bid version %d, want %d", got.Version, bid.Version+2)
+    }
+
+    f.lots.tenders = f.repo
+    _, lot, err := f.lots.Award(tender.ID, north, bid.ID, "alice", AnyVersion)
+    f.must(err)
+    if lot.AwardedBidID != bid.ID || f.bidStatus(bid.ID) != model.BidApproved {
+        t.Fatal("award after a failed one did not go through")
+    }
+}
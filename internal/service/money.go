@@ -0,0 +1,36 @@
+package service
+
+import (
+    "errors"
+    "fmt"
+
+    "tender/internal/model"
+)
+
+// ErrCurrencyMismatch is returned for a price in another currency than the
+// budget or auction of its tender.
+var ErrCurrencyMismatch = errors.New("price is in another currency than the tender")
+
+// BudgetError reports a price above the budget of the tender.
+type BudgetError struct {
+    Budget model.Money
+}
+
+func (e *BudgetError) Error() string {
+    return fmt.Sprintf("price exceeds the budget of %s", e.Budget)
+}
+
+// checkBudget checks that price fits the budget of t, if it has one: same
+// currency, and no more than the budget.
+func checkBudget(t model.Tender, price model.Money) error {
+    if t.Budget == nil {
+        return nil
+    }
+    if price.Currency != t.Budget.Currency {
+        return fmt.Errorf("%w: the budget is in %s", ErrCurrencyMismatch, t.Budget.Currency)
+    }
+    if price.Amount > t.Budget.Amount {
+        return &BudgetError{Budget: *t.Budget}
+    }
+    return nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+    "encoding/base64"
+    "encoding/json"
+    "slices"
+    "sort"
+    "strings"
+    "time"
+)
+
+const (
+    // DefaultLimit and MaxLimit follow the paginationLimit parameter of
+    // openapi.yml.
+    DefaultLimit = 5
+    MaxLimit     = 50
+)
+
+// Page selects a window of a list. Either Offset or Cursor may be used; the
+// cursor is the opaque token returned with the previous page and stays
+// stable when items are inserted before it.
+type Page struct {
+    Limit  int
+    Offset int
+    Cursor string
+}
+
+func (p Page) validate() error {
+    if p.Limit < 0 || p.Limit > MaxLimit || p.Offset < 0 {
+        return ErrInvalidPage
+    }
+    if p.Cursor != "" && p.Offset != 0 {
+        return ErrInvalidPage
+    }
+    return nil
+}
+
+// sortKey orders list items: by Primary, then by ID as a tie-breaker so
+// that pages never overlap or skip items with equal names.
+type sortKey struct {
+    Primary string `json:"p"`
+    ID      string `json:"id"`
+}
+
+func (k sortKey) compare(o sortKey) int {
+    if c := strings.Compare(k.Primary, o.Primary); c != 0 {
+        return c
+    }
+    return strings.Compare(k.ID, o.ID)
+}
+
+// timeKey renders t so that lexical order matches chronological order.
+func timeKey(t time.Time) string {
+    return t.UTC().Format("2006-01-02T15:04:05.000000000")
+}
+
+func encodeCursor(k sortKey) string {
+    b, _ := json.Marshal(k)
+    return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeCursor accepts only cursors encodeCursor could have returned, so
+// that a tampered one is refused rather than read as some other position.
+func decodeCursor(s string) (sortKey, error) {
+    var k sortKey
+    b, err := base64.RawURLEncoding.DecodeString(s)
+    if err != nil {
+        return k, ErrInvalidPage
+    }
+    if err := json.Unmarshal(b, &k); err != nil || encodeCursor(k) != s {
+        return sortKey{}, ErrInvalidPage
+    }
+    return k, nil
+}
+
+// paginate sorts items by key (descending when desc is set) and returns the
+// window selected by p together with the cursor of the next page, which is
+// empty on the last page. The result is never nil.
+func paginate[T any](items []T, p Page, key func(T) sortKey, desc bool) ([]T, string, error) {
+    if err := p.validate(); err != nil {
+        return nil, "", err
+    }
+    cmp := func(a, b sortKey) int {
+        if desc {
+            return b.compare(a)
+        }
+        return a.compare(b)
+    }
+    slices.SortFunc(items, func(a, b T) int { return cmp(key(a), key(b)) })
+
+    start := p.Offset
+    if p.Cursor != "" {
+        after, err := decodeCursor(p.Cursor)
+        if err != nil {
+            return nil, "", err
+        }
+        start = sort.Search(len(items), func(i int) bool { return cmp(key(items[i]), after) > 0 })
+    }
+    start = min(start, len(items))
+    end := min(start+p.Limit, len(items))
+
+    res := append(make([]T, 0, end-start), items[start:end]...)
+    var next string
+    if end > start && end < len(items) {
+        next = encodeCursor(key(items[end-1]))
+    }
+    return res, next, nil
+}
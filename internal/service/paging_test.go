@@ -0,0 +1,154 @@
+package service
+
+import (
+    "encoding/base64"
+    "fmt"
+    "slices"
+    "testing"
+
+    "tender/internal/model"
+)
+
+// item is a list entry ordered by name, then ID.
+type item struct{ name, id string }
+
+func itemKey(i item) sortKey {
+    return sortKey{Primary: i.name, ID: i.id}
+}
+
+// pageAll follows the cursors of paginate from the first page to the last
+// and returns every item seen.
+func pageAll[T any](t *testing.T, items []T, limit int, key func(T) sortKey, desc bool) []T {
+    t.Helper()
+    var (
+        res    []T
+        cursor string
+    )
+    for range len(items) + 1 {
+        page, next, err := paginate(slices.Clone(items), Page{Limit: limit, Cursor: cursor}, key, desc)
+        if err != nil {
+            t.Fatal(err)
+        }
+        res = append(res, page...)
+        if next == "" {
+            return res
+        }
+        cursor = next
+    }
+    t.Fatalf("paging %d items by %d does not end", len(items), limit)
+    return nil
+}
+
+func TestPaginateCursors(t *testing.T) {
+    // b and c tie on their name, and so do e and f across every page
+    // boundary below.
+    items := []item{
+        {"delta", "f"}, {"alpha", "a"}, {"bravo", "c"}, {"bravo", "b"},
+        {"delta", "e"}, {"charlie", "d"}, {"delta", "g"},
+    }
+    asc := []item{
+        {"alpha", "a"}, {"bravo", "b"}, {"bravo", "c"}, {"charlie", "d"},
+        {"delta", "e"}, {"delta", "f"}, {"delta", "g"},
+    }
+    desc := slices.Clone(asc)
+    slices.Reverse(desc)
+
+    for limit := 1; limit <= len(items)+1; limit++ {
+        for _, c := range []struct {
+            desc bool
+            want []item
+        }{{false, asc}, {true, desc}} {
+            t.Run(fmt.Sprintf("limit %d desc %v", limit, c.desc), func(t *testing.T) {
+                got := pageAll(t, items, limit, itemKey, c.desc)
+                if !slices.Equal(got, c.want) {
+                    t.Fatalf("got %v, want %v", got, c.want)
+                }
+            })
+        }
+    }
+}
+
+func TestPaginateCursorIsStable(t *testing.T) {
+    items := []item{{"a", "1"}, {"b", "2"}, {"c", "3"}, {"d", "4"}}
+    page, next, err := paginate(slices.Clone(items), Page{Limit: 2}, itemKey, false)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !slices.Equal(page, items[:2]) || next == "" {
+        t.Fatalf("first page %v, next %q", page, next)
+    }
+    // Items inserted before the cursor, even one tying with its last item,
+    // do not shift the next page.
+    items = append(items, item{"0", "0"}, item{"b", "1"})
+    page, next, err = paginate(items, Page{Limit: 2, Cursor: next}, itemKey, false)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if want := []item{{"c", "3"}, {"d", "4"}}; !slices.Equal(page, want) || next != "" {
+        t.Fatalf("second page %v, next %q, want %v and no next", page, next, want)
+    }
+}
+
+func TestPaginateRejects(t *testing.T) {
+    b64 := base64.RawURLEncoding.EncodeToString
+    valid := encodeCursor(sortKey{Primary: "b", ID: "2"})
+    cases := []struct {
+        name string
+        page Page
+    }{
+        {"negative limit", Page{Limit: -1}},
+        {"limit above max", Page{Limit: MaxLimit + 1}},
+        {"negative offset", Page{Limit: 1, Offset: -1}},
+        {"cursor and offset", Page{Limit: 1, Offset: 1, Cursor: valid}},
+        {"not base64", Page{Limit: 1, Cursor: "%%%"}},
+        {"padded base64", Page{Limit: 1, Cursor: base64.URLEncoding.EncodeToString([]byte(`{"p":"bb","id":"2"}`))}},
+        {"not JSON", Page{Limit: 1, Cursor: b64([]byte("b/2"))}},
+        {"JSON array", Page{Limit: 1, Cursor: b64([]byte(`["b","2"]`))}},
+        {"wrong type", Page{Limit: 1, Cursor: b64([]byte(`{"p":1,"id":"2"}`))}},
+        {"unknown field", Page{Limit: 1, Cursor: b64([]byte(`{"p":"b","id":"2","x":1}`))}},
+        {"reordered fields", Page{Limit: 1, Cursor: b64([]byte(`{"id":"2","p":"b"}`))}},
+        {"spaces", Page{Limit: 1, Cursor: b64([]byte(`{"p": "b", "id": "2"}`))}},
+        {"trailing data", Page{Limit: 1, Cursor: valid + "AA"}},
+    }
+    items := []item{{"a", "1"}, {"b", "2"}, {"c", "3"}}
+    for _, c := range cases {
+        t.Run(c.name, func(t *testing.T) {
+            _, _, err := paginate(slices.Clone(items), c.page, itemKey, false)
+            wantErr(t, err, ErrInvalidPage)
+        })
+    }
+
+    // A well-formed cursor past the end is an empty last page.
+    page, next, err := paginate(items, Page{Limit: 1, Cursor: encodeCursor(sortKey{Primary: "z"})}, itemKey, false)
+    if err != nil || len(page) != 0 || page == nil || next != "" {
+        t.Fatalf("past the end: %v %q %v, want an empty last page", page, next, err)
+    }
+}
+
+func TestPaginateBidsByPrice(t *testing.T) {
+    price := func(amount model.Amount, currency string) *model.Money {
+        return &model.Money{Amount: amount, Currency: currency}
+    }
+    bids := []model.Bid{
+        {ID: "h", Name: "none"},
+        {ID: "f", Price: price(50, "USD")},
+        {ID: "c", Price: price(100, "RUB")},
+        {ID: "e", Price: price(1_000_000_000, "RUB")},
+        {ID: "b", Price: price(100, "RUB")},
+        {ID: "g", Name: "none"},
+        {ID: "a", Price: price(9, "RUB")},
+        {ID: "d", Price: price(200, "RUB")},
+    }
+    // By currency, cheapest first with ties by ID, the unpriced last.
+    want := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+    for limit := 1; limit <= len(bids); limit++ {
+        got := pageAll(t, bids, limit, bidOrder(BidsByPrice), false)
+        var ids []string
+        for _, b := range got {
+            ids = append(ids, b.ID)
+        }
+        if !slices.Equal(ids, want) {
+            t.Fatalf("limit %d: got %v, want %v", limit, ids, want)
+        }
+    }
+}
@@ -0,0 +1,294 @@
+package service
+
+import (
+    "errors"
+    "fmt"
+    "time"
+
+    "github.com/google/uuid"
+
+    "tender/internal/model"
+    "tender/internal/storage"
+)
+
+// QuestionService runs the clarification board of tenders: anyone can ask
+// on a published tender, and responsibles of its organization answer.
+type QuestionService struct {
+    repo    storage.QuestionRepository
+    tenders storage.TenderRepository
+    audit   auditor
+    access  access
+    clock   Clock
+}
+
+func NewQuestionService(r storage.QuestionRepository, tenders storage.TenderRepository, users storage.UserRepository) *QuestionService {
+    return &QuestionService{repo: r, tenders: tenders, access: access{users: users}, clock: SystemClock}
+}
+
+// WithRequestID returns a copy of s that records its changes in the audit
+// log as made by the request id.
+func (s *QuestionService) WithRequestID(id string) *QuestionService {
+    c := *s
+    c.audit.requestID = id
+    return &c
+}
+
+// Ask asks a question on a published tender that still accepts bids.
+func (s *QuestionService) Ask(tenderID, username, text string) (model.Question, error) {
+    user, err := s.access.employee(username)
+    if err != nil {
+        return model.Question{}, err
+    }
+    tender, err := visibleTender(s.tenders, s.access, tenderID, username)
+    if err != nil {
+        return model.Question{}, err
+    }
+    if tender.Status != model.TenderPublished {
+        return model.Question{}, &StateError{Entity: "tender", Status: tender.Status, Action: "ask about"}
+    }
+    now := s.clock.Now()
+    if err := checkSubmission(tender, now, "ask a question"); err != nil {
+        return model.Question{}, err
+    }
+    q := model.Question{
+        ID:       uuid.New().String(),
+        TenderID: tenderID,
+        Text:     text,
+        AskedBy:  user.Username,
+        AskedAt:  now,
+        Version:  1,
+    }
+    if err := s.repo.AddQuestion(q, s.audit.question(model.AuditQuestionAsk, user.Username, 0, q, now)); err != nil {
+        return model.Question{}, err
+    }
+    return q, nil
+}
+
+// List returns a page of the questions on a tender that the optional
+// username may see, oldest first: every question for responsibles of the
+// tender's organization, and otherwise the published ones and those the
+// user asked.
+func (s *QuestionService) List(tenderID, username string, p Page) ([]model.Question, string, error) {
+    tender, err := visibleTender(s.tenders, s.access, tenderID, username)
+    if err != nil {
+        return nil, "", err
+    }
+    reader, err := s.reader(tender, username)
+    if err != nil {
+        return nil, "", err
+    }
+    questions, err := s.repo.QuestionsByTender(tenderID)
+    if err != nil {
+        return nil, "", err
+    }
+    var res []model.Question
+    for _, q := range questions {
+        if q, ok := reader.view(q); ok {
+            res = append(res, q)
+        }
+    }
+    return paginate(res, p, questionKey, false)
+}
+
+// Get returns a question on a tender if the optional username may see it.
+// Questions the user may not see are reported as not found.
+func (s *QuestionService) Get(tenderID, questionID, username string) (model.Question, error) {
+    tender, err := visibleTender(s.tenders, s.access, tenderID, username)
+    if err != nil {
+        return model.Question{}, err
+    }
+    reader, err := s.reader(tender, username)
+    if err != nil {
+        return model.Question{}, err
+    }
+    q, err := s.question(tenderID, questionID)
+    if err != nil {
+        return model.Question{}, err
+    }
+    q, ok := reader.view(q)
+    if !ok {
+        return model.Question{}, &NotFoundError{Entity: "question", ID: questionID}
+    }
+    return q, nil
+}
+
+// Answer answers a question on behalf of a responsible of the tender's
+// organization, replacing any earlier answer. With publish the answer is
+// shown to everyone, without the asker; a published answer cannot be
+// withdrawn. A material clarification may push the submission deadline of
+// the tender to deadline, moving its decision deadline, and the end of its
+// auction, along.
+func (s *QuestionService) Answer(tenderID, questionID, username, answer string, publish bool, deadline *time.Time) (model.Question, error) {
+    if _, err := s.question(tenderID, questionID); err != nil {
+        return model.Question{}, err
+    }
+    tender, err := getTender(s.tenders, tenderID)
+    if err != nil {
+        return model.Question{}, err
+    }
+    user, err := s.access.responsible(username, tender.OrganizationID)
+    if err != nil {
+        return model.Question{}, err
+    }
+    now := s.clock.Now()
+    var until time.Time
+    if deadline != nil {
+        // Refuse an extension that cannot be made before answering; the
+        // tender may still change in between, which is checked again below.
+        until = deadline.UTC()
+        if err := extendDeadline(&tender, until, now); err != nil {
+            return model.Question{}, err
+        }
+    }
+    var old model.Question
+    q, err := s.update(model.AuditQuestionAnswer, user.Username, tenderID, questionID, now, func(q *model.Question) error {
+        old = *q
+        q.Answer = answer
+        q.AnsweredBy = user.Username
+        q.AnsweredAt = &now
+        q.Published = q.Published || publish
+        if deadline != nil {
+            q.DeadlineExtended = &until
+        }
+        q.Version++
+        return nil
+    })
+    if err != nil {
+        return model.Question{}, err
+    }
+    if deadline != nil {
+        _, err := s.audit.updateTender(s.tenders, model.AuditTenderExtend, user.Username, tenderID, AnyVersion, func(t *model.Tender) error {
+            if err := extendDeadline(t, until, now); err != nil {
+                return err
+            }
+            bumpTender(t, user.Username, now)
+            return nil
+        })
+        if err != nil {
+            return model.Question{}, errors.Join(err, s.unanswer(old, q, user, now))
+        }
+    }
+    return q, nil
+}
+
+// unanswer restores the question answered to old after the deadline
+// extension that came with the answer failed, unless it changed since.
+func (s *QuestionService) unanswer(old, answered model.Question, user model.Employee, now time.Time) error {
+    _, err := s.update(model.AuditQuestionUnanswer, user.Username, answered.TenderID, answered.ID, now, func(q *model.Question) error {
+        if q.Version != answered.Version {
+            return errUnchanged
+        }
+        *q = old
+        q.Version = answered.Version + 1
+        return nil
+    })
+    return err
+}
+
+// update applies fn to a question on tenderID and stores the result, with
+// its event as action by actor at now, by a compare-and-swap on its
+// version, as updateTender does for tenders.
+func (s *QuestionService) update(action, actor, tenderID, id string, now time.Time, fn func(*model.Question) error) (model.Question, error) {
+    for range maxAttempts {
+        q, err := s.question(tenderID, id)
+        if err != nil {
+            return model.Question{}, err
+        }
+        expected := q.Version
+        if err := fn(&q); errors.Is(err, errUnchanged) {
+            return q, nil
+        } else if err != nil {
+            return model.Question{}, err
+        }
+        err = s.repo.UpdateQuestion(q, expected, s.audit.question(action, actor, expected, q, now))
+        if errors.Is(err, storage.ErrConflict) {
+            continue
+        }
+        if err != nil {
+            return model.Question{}, notFound(err, "question", id)
+        }
+        return q, nil
+    }
+    return model.Question{}, ErrConflict
+}
+
+// extendDeadline pushes the submission deadline of a published tender that
+// still accepts bids to until, which must be later. The decision deadline
+// and the end of an auction move by as much.
+func extendDeadline(t *model.Tender, until, now time.Time) error {
+    if t.Status != model.TenderPublished {
+        return &StateError{Entity: "tender", Status: t.Status, Action: "extend the deadline of"}
+    }
+    if err := checkSubmission(*t, now, "extend the deadline"); err != nil {
+        return err
+    }
+    if t.SubmissionDeadline == nil {
+        return fmt.Errorf("%w: the tender has no submission deadline to extend", ErrInvalidDeadline)
+    }
+    if !until.After(*t.SubmissionDeadline) {
+        return fmt.Errorf("%w: the extended deadline must be after %s", ErrInvalidDeadline,
+            t.SubmissionDeadline.Format(time.RFC3339))
+    }
+    shift := until.Sub(*t.SubmissionDeadline)
+    if t.DecisionDeadline != nil {
+        decision := t.DecisionDeadline.Add(shift)
+        t.DecisionDeadline = &decision
+    }
+    if t.Auction != nil {
+        // The stored tender shares the auction; change a copy.
+        a := *t.Auction
+        a.EndsAt = until
+        t.Auction = &a
+    }
+    t.SubmissionDeadline = &until
+    return nil
+}
+
+// question returns a question on tenderID.
+func (s *QuestionService) question(tenderID, id string) (model.Question, error) {
+    q, err := s.repo.GetQuestion(id)
+    if err == nil && q.TenderID != tenderID {
+        err = storage.ErrNotFound
+    }
+    return q, notFound(err, "question", id)
+}
+
+// questionReader is who reads the questions on a tender.
+type questionReader struct {
+    username    string
+    responsible bool
+}
+
+// reader resolves the optional username reading the questions on t.
+func (s *QuestionService) reader(t model.Tender, username string) (questionReader, error) {
+    if username == "" {
+        return questionReader{}, nil
+    }
+    user, err := s.access.employee(username)
+    if err != nil {
+        return questionReader{}, err
+    }
+    ok, err := s.access.isResponsible(user, t.OrganizationID)
+    if err != nil {
+        return questionReader{}, err
+    }
+    return questionReader{username: user.Username, responsible: ok}, nil
+}
+
+// view returns q as r may see it: whole to responsibles and the asker,
+// without the asker to everyone else once published.
+func (r questionReader) view(q model.Question) (model.Question, bool) {
+    if r.responsible || (r.username != "" && q.AskedBy == r.username) {
+        return q, true
+    }
+    if !q.Published {
+        return model.Question{}, false
+    }
+    q.AskedBy = ""
+    return q, true
+}
+
+// questionKey orders questions by the time they were asked.
+func questionKey(q model.Question) sortKey {
+    return sortKey{Primary: timeKey(q.AskedAt), ID: q.ID}
+}
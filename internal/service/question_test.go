@@ -0,0 +1,123 @@
+package service
+
+import (
+    "slices"
+    "testing"
+    "time"
+
+    "tender/internal/model"
+    "tender/internal/storage"
+)
+
+// ask asks a question as erin on tenderID.
+func (f *fixture) ask(tenderID string) model.Question {
+    f.t.Helper()
+    q, err := f.questions.Ask(tenderID, "erin", "When?")
+    f.must(err)
+    return q
+}
+
+// wantQuestion fails the test unless question id is stored at version
+// with answer.
+func (f *fixture) wantQuestion(id string, version int, answer string) model.Question {
+    f.t.Helper()
+    q, err := f.repo.GetQuestion(id)
+    f.must(err)
+    if q.Version != version || q.Answer != answer {
+        f.t.Fatalf("question at version %d with answer %q, want %d with %q", q.Version, q.Answer, version, answer)
+    }
+    return q
+}
+
+func TestAnswerExtendsDeadline(t *testing.T) {
+    f := newFixture(t)
+    tender := f.tender(Deadlines{Submission: f.at(time.Hour), Decision: f.at(2 * time.Hour)}, false)
+    q := f.ask(tender.ID)
+
+    got, err := f.questions.Answer(tender.ID, q.ID, "alice", "Soon", true, f.at(3*time.Hour))
+    f.must(err)
+    if got.Version != 2 || !got.Published || got.DeadlineExtended == nil || !got.DeadlineExtended.Equal(*f.at(3 * time.Hour)) {
+        t.Fatalf("answered question %+v", got)
+    }
+    f.wantQuestion(q.ID, 2, "Soon")
+    tender, err = f.repo.GetTender(tender.ID)
+    f.must(err)
+    if !tender.SubmissionDeadline.Equal(*f.at(3 * time.Hour)) || !tender.DecisionDeadline.Equal(*f.at(4 * time.Hour)) {
+        t.Fatalf("deadlines %v and %v after the extension", tender.SubmissionDeadline, tender.DecisionDeadline)
+    }
+}
+
+func TestAnswerRefusesBadExtension(t *testing.T) {
+    f := newFixture(t)
+    tender := f.tender(Deadlines{Submission: f.at(time.Hour)}, false)
+    q := f.ask(tender.ID)
+
+    _, err := f.questions.Answer(tender.ID, q.ID, "alice", "Soon", true, f.at(time.Minute))
+    wantErr(t, err, ErrInvalidDeadline)
+    f.wantQuestion(q.ID, 1, "")
+}
+
+func TestFailedExtensionTakesAnswerBack(t *testing.T) {
+    f := newFixture(t)
+    tender := f.tender(Deadlines{Submission: f.at(time.Hour)}, false)
+    q := f.ask(tender.ID)
+
+    f.questions.tenders = failingTenders{f.repo}
+    _, err := f.questions.Answer(tender.ID, q.ID, "alice", "Soon", true, f.at(3*time.Hour))
+    wantErr(t, err, errDiskFull)
+    // Answering and taking it back are both versions of the question.
+    got := f.wantQuestion(q.ID, 3, "")
+    if got.Answered() || got.Published || got.DeadlineExtended != nil {
+        t.Fatalf("question %+v after a failed extension", got)
+    }
+    events, err := f.repo.ListAudit(storage.AuditFilter{EntityID: q.ID})
+    f.must(err)
+    var actions []string
+    for _, e := range events {
+        actions = append(actions, e.Action)
+    }
+    want := []string{model.AuditQuestionAsk, model.AuditQuestionAnswer, model.AuditQuestionUnanswer}
+    if !slices.Equal(actions, want) {
+        t.Fatalf("audit actions %v, want %v", actions, want)
+    }
+}
+
+// racingQuestions is a question store in which another answer is stored
+// just before the first update.
+type racingQuestions struct {
+    storage.QuestionRepository
+    raced bool
+}
+
+func (r *racingQuestions) UpdateQuestion(q model.Question, expected int, e model.AuditEvent) error {
+    if !r.raced {
+        r.raced = true
+        other, err := r.GetQuestion(q.ID)
+        if err != nil {
+            return err
+        }
+        other.Answer = "Later"
+        other.Published = true
+        other.Version++
+        if err := r.QuestionRepository.UpdateQuestion(other, expected, e); err != nil {
+            return err
+        }
+    }
+    return r.QuestionRepository.UpdateQuestion(q, expected, e)
+}
+
+func TestAnswerRetriesOnConflict(t *testing.T) {
+    f := newFixture(t)
+    tender := f.tender(Deadlines{Submission: f.at(time.Hour)}, false)
+    q := f.ask(tender.ID)
+
+    f.questions.repo = &racingQuestions{QuestionRepository: f.repo}
+    got, err := f.questions.Answer(tender.ID, q.ID, "alice", "Soon", false, nil)
+    f.must(err)
+    // The answer is applied again to the question as the other answer left
+    // it, which published it for good.
+    if got.Version != 3 || got.Answer != "Soon" || !got.Published {
+        t.Fatalf("answered question %+v", got)
+    }
+    f.wantQuestion(q.ID, 3, "Soon")
+}
@@ -0,0 +1,29 @@
+package service
+
+import "tender/internal/model"
+
+// maxQuorum caps the number of approvals a bid needs.
+const maxQuorum = 3
+
+// quorum is min(3, number of organization responsibles), never below one.
+func quorum(responsibles int) int {
+    return max(1, min(maxQuorum, responsibles))
+}
+
+// evaluate returns the outcome of the votes cast so far: Rejected as soon as
+// anyone rejects, Approved once approvals reach need, and "" while pending.
+func evaluate(votes []model.BidDecision, need int) string {
+    approvals := 0
+    for _, v := range votes {
+        switch v.Decision {
+        case model.DecisionRejected:
+            return model.DecisionRejected
+        case model.DecisionApproved:
+            approvals++
+        }
+    }
+    if approvals >= need {
+        return model.DecisionApproved
+    }
+    return ""
+}
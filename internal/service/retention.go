@@ -0,0 +1,31 @@
+package service
+
+import (
+    "time"
+
+    "tender/internal/storage"
+)
+
+// Retention limits the archived versions kept in the history store. The
+// current state of a tender or bid is never pruned.
+type Retention struct {
+    // KeepLast keeps at most this many archived versions per tender or bid.
+    KeepLast int
+    // MaxAge drops archived versions produced longer ago than this.
+    MaxAge time.Duration
+}
+
+// Enabled reports whether r limits anything.
+func (r Retention) Enabled() bool {
+    return r.KeepLast > 0 || r.MaxAge > 0
+}
+
+// Prune applies r to repo as of now and returns how many versions it
+// deleted.
+func (r Retention) Prune(repo storage.VersionRepository, now time.Time) (int, error) {
+    var cutoff time.Time
+    if r.MaxAge > 0 {
+        cutoff = now.Add(-r.MaxAge)
+    }
+    return repo.PruneVersions(r.KeepLast, cutoff)
+}
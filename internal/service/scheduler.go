@@ -0,0 +1,88 @@
+package service
+
+import (
+    "errors"
+    "slices"
+    "time"
+
+    "tender/internal/model"
+    "tender/internal/storage"
+)
+
+// Scheduler closes tenders once their closing deadline has passed: the
+// decision deadline when they have one, the end of DecisionWindow after the
+// submission deadline otherwise.
+type Scheduler struct {
+    tenders storage.TenderRepository
+    bids    storage.BidRepository
+    audit   auditor
+    clock   Clock
+
+    // DecisionWindow is how long a tender without a decision deadline stays
+    // open for a decision after its submission deadline. Without a window
+    // such tenders stay open until a bid is approved.
+    DecisionWindow time.Duration
+}
+
+func NewScheduler(tenders storage.TenderRepository, bids storage.BidRepository, clock Clock) *Scheduler {
+    return &Scheduler{tenders: tenders, bids: bids, clock: clock}
+}
+
+// CloseExpired closes every tender that is not closed yet and whose closing
+// deadline has passed, and returns how many it closed. Each closing is a new
+// version of the tender produced by no user, so it shows up in the tender's
+// history and in the audit log. Closing cancels the lots still open and, as
+// a decision would, rejects the bids still undecided. A tender that fails to
+// close does not stop the others.
+func (s *Scheduler) CloseExpired() (int, error) {
+    now := s.clock.Now()
+    expired, err := s.tenders.ListTenders(storage.TenderFilter{
+        Statuses:       []string{model.TenderCreated, model.TenderPublished},
+        ExpiredBy:      now,
+        DecisionWindow: s.DecisionWindow,
+    })
+    if err != nil {
+        return 0, err
+    }
+    var (
+        closed int
+        errs   []error
+    )
+    for _, t := range expired {
+        var changed bool
+        _, err := s.audit.updateTender(s.tenders, model.AuditTenderClose, "", t.ID, AnyVersion, func(t *model.Tender) error {
+            // Reloaded tenders may have been closed or given a new deadline
+            // in the meantime.
+            changed = t.Status != model.TenderClosed && t.Expired(now, s.DecisionWindow)
+            if !changed {
+                return errUnchanged
+            }
+            t.Status = model.TenderClosed
+            t.Lots = cancelOpenLots(t.Lots, now)
+            bumpTender(t, "", now)
+            return nil
+        })
+        if err == nil && changed {
+            closed++
+            err = rejectOthers(s.bids, s.audit, t.ID, "", "", now)
+        }
+        if err != nil {
+            errs = append(errs, err)
+        }
+    }
+    return closed, errors.Join(errs...)
+}
+
+// cancelOpenLots returns lots with those still open canceled by no user at
+// now. The stored tender shares its lots, so it changes a copy.
+func cancelOpenLots(lots []model.Lot, now time.Time) []model.Lot {
+    lots = slices.Clone(lots)
+    for i := range lots {
+        if lots[i].Status == model.LotOpen {
+            lots[i].Status = model.LotCanceled
+            lots[i].UpdatedBy = ""
+            lots[i].UpdatedAt = now
+        }
+    }
+    return lots
+}
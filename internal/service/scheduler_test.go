@@ -0,0 +1,146 @@
+package service
+
+import (
+    "testing"
+    "time"
+
+    "tender/internal/model"
+    "tender/internal/storage"
+)
+
+func TestSchedulerClosesExpiredTenders(t *testing.T) {
+    f := newFixture(t)
+    f.scheduler.DecisionWindow = 2 * time.Hour
+    start := f.clock.Now()
+    submission := f.tender(Deadlines{Submission: f.at(time.Hour)}, false)
+    decision := f.tender(Deadlines{Submission: f.at(time.Hour), Decision: f.at(2 * time.Hour)}, false)
+    open := f.tender(Deadlines{}, false)
+    created, err := f.tenders.Create("Draft", "d", "Delivery", orgA, "alice", nil, Deadlines{Submission: f.at(time.Hour)}, false, nil, nil, nil)
+    f.must(err)
+
+    closeExpired := func(want int) {
+        t.Helper()
+        n, err := f.scheduler.CloseExpired()
+        f.must(err)
+        if n != want {
+            t.Fatalf("at %v: closed %d tenders, want %d", f.clock.Now().Sub(start), n, want)
+        }
+    }
+
+    closeExpired(0)
+    // Passing the submission deadline leaves time for a decision.
+    f.clock.advance(time.Hour)
+    closeExpired(0)
+    f.clock.advance(time.Hour)
+    closeExpired(1)
+    f.wantTenderStatus(decision.ID, model.TenderClosed)
+    f.wantTenderStatus(submission.ID, model.TenderPublished)
+    // Without a decision deadline the window after submission closes.
+    f.clock.advance(time.Hour)
+    closeExpired(2)
+    closeExpired(0)
+    f.wantTenderStatus(submission.ID, model.TenderClosed)
+    f.wantTenderStatus(created.ID, model.TenderClosed)
+    f.wantTenderStatus(open.ID, model.TenderPublished)
+
+    // The closing is a version of its own, made by no user at the time the
+    // scheduler ran.
+    v, err := f.tenders.Version(decision.ID, 3, "alice")
+    f.must(err)
+    if v.Status != model.TenderClosed || v.UpdatedBy != "" || !v.UpdatedAt.Equal(start.Add(2*time.Hour)) {
+        t.Fatalf("closing version = %s by %q at %v", v.Status, v.UpdatedBy, v.UpdatedAt)
+    }
+}
+
+func TestSchedulerLeavesSubmissionDeadlineForDecision(t *testing.T) {
+    f := newFixture(t)
+    tender := f.tender(Deadlines{Submission: f.at(time.Hour)}, false)
+    bid := f.bid(tender.ID, nil)
+    auction := f.auction()
+    offer := f.bid(auction.ID, rub(1000))
+
+    // Without a decision window, tenders and auctions that only have a
+    // submission deadline wait for their decision however long it takes.
+    f.clock.advance(30 * 24 * time.Hour)
+    n, err := f.scheduler.CloseExpired()
+    f.must(err)
+    if n != 0 {
+        t.Fatalf("closed %d tenders without a decision deadline", n)
+    }
+    for _, id := range []string{bid.ID, offer.ID} {
+        _, err := f.approve(id, f.bids)
+        f.must(err)
+        if status := f.bidStatus(id); status != model.BidApproved {
+            t.Fatalf("bid %s after the submission deadline, want %s", status, model.BidApproved)
+        }
+    }
+    f.wantTenderStatus(tender.ID, model.TenderClosed)
+    f.wantTenderStatus(auction.ID, model.TenderClosed)
+}
+
+func TestSchedulerResolvesBidsAndLots(t *testing.T) {
+    f := newFixture(t)
+    lots := []model.Lot{{Name: "North", Quantity: 1}, {Name: "South", Quantity: 1}}
+    tender, err := f.tenders.Create("Lots", "d", "Delivery", orgA, "alice", nil,
+        Deadlines{Submission: f.at(time.Hour), Decision: f.at(2 * time.Hour)}, false, nil, nil, lots)
+    f.must(err)
+    _, err = f.tenders.UpdateStatus(tender.ID, model.TenderPublished, "alice", AnyVersion)
+    f.must(err)
+    north, south := tender.Lots[0].ID, tender.Lots[1].ID
+    winner := f.lotBid(tender.ID, north)
+    loser := f.lotBid(tender.ID, south)
+    draft, err := f.bids.Create("Draft", "d", tender.ID, model.AuthorUser, employeeID("dave"), nil, []string{south})
+    f.must(err)
+    _, _, err = f.lots.Award(tender.ID, north, winner.ID, "alice", AnyVersion)
+    f.must(err)
+
+    f.clock.advance(2 * time.Hour)
+    n, err := f.scheduler.CloseExpired()
+    f.must(err)
+    if n != 1 {
+        t.Fatalf("closed %d tenders, want 1", n)
+    }
+    closed, err := f.repo.GetTender(tender.ID)
+    f.must(err)
+    if closed.Status != model.TenderClosed {
+        t.Fatalf("tender %s, want %s", closed.Status, model.TenderClosed)
+    }
+    // Closing resolves what a decision would have: open lots are canceled
+    // and undecided bids rejected, while the award stands.
+    for _, want := range []struct{ id, status string }{{north, model.LotAwarded}, {south, model.LotCanceled}} {
+        if l, _ := closed.Lot(want.id); l.Status != want.status {
+            t.Errorf("lot %s %s, want %s", l.Name, l.Status, want.status)
+        }
+    }
+    for _, want := range []struct{ id, status string }{
+        {winner.ID, model.BidApproved}, {loser.ID, model.BidRejected}, {draft.ID, model.BidRejected},
+    } {
+        if status := f.bidStatus(want.id); status != want.status {
+            t.Errorf("bid %s, want %s", status, want.status)
+        }
+    }
+    events, err := f.repo.ListAudit(storage.AuditFilter{TenderIDs: []string{tender.ID}, Action: model.AuditBidReject})
+    f.must(err)
+    rejections := 0
+    for _, e := range events {
+        if e.Actor == "" {
+            rejections++
+        }
+    }
+    if rejections != 2 {
+        t.Fatalf("%d rejections by the scheduler in the audit log, want 2", rejections)
+    }
+}
+
+func TestSchedulerSkipsExtendedDeadline(t *testing.T) {
+    f := newFixture(t)
+    tender := f.tender(Deadlines{Submission: f.at(time.Hour)}, false)
+    _, err := f.tenders.Edit(tender.ID, "alice", AnyVersion, nil, nil, nil, nil, Deadlines{Submission: f.at(2 * time.Hour)}, nil)
+    f.must(err)
+    f.clock.advance(time.Hour)
+    n, err := f.scheduler.CloseExpired()
+    f.must(err)
+    if n != 0 {
+        t.Fatalf("closed %d tenders before the extended deadline", n)
+    }
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+    "encoding/json"
+    "errors"
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+
+    "tender/internal/model"
+    "tender/internal/storage"
+    "tender/internal/storage/blob"
+)
+
+// The test directory: alice and bob are responsible for orgA, dave for
+// orgB, and erin for no organization.
+const (
+    orgA = "11111111-1111-1111-1111-111111111111"
+    orgB = "22222222-2222-2222-2222-222222222222"
+)
+
+var testEmployees = []model.Employee{
+    {ID: "00000001-0000-0000-0000-000000000000", Username: "alice"},
+    {ID: "00000002-0000-0000-0000-000000000000", Username: "bob"},
+    {ID: "00000004-0000-0000-0000-000000000000", Username: "dave"},
+    {ID: "00000005-0000-0000-0000-000000000000", Username: "erin"},
+}
+
+// employeeID returns the ID of the test employee username.
+func employeeID(username string) string {
+    for _, e := range testEmployees {
+        if e.Username == username {
+            return e.ID
+        }
+    }
+    panic("unknown test employee " + username)
+}
+
+// fakeClock is a Clock that only moves when told to.
+type fakeClock struct {
+    now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+    return c.now
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+    c.now = c.now.Add(d)
+}
+
+// fixture is a set of services over a fresh file store holding the test
+// directory, all running on one fake clock.
+type fixture struct {
+    t           *testing.T
+    path        string
+    repo        *storage.Storage
+    clock       *fakeClock
+    tenders     *TenderService
+    bids        *BidService
+    attachments *AttachmentService
+    lots        *LotService
+    questions   *QuestionService
+    scheduler   *Scheduler
+    audit       *AuditService
+}
+
+func newFixture(t *testing.T) *fixture {
+    t.Helper()
+    path := filepath.Join(t.TempDir(), "data.json")
+    data, err := json.Marshal(storage.Data{
+        Employees:     testEmployees,
+        Organizations: []model.Organization{{ID: orgA, Name: "A"}, {ID: orgB, Name: "B"}},
+        Responsibles: []model.OrganizationResponsible{
+            {ID: "r1", OrganizationID: orgA, UserID: employeeID("alice")},
+            {ID: "r2", OrganizationID: orgA, UserID: employeeID("bob")},
+            {ID: "r3", OrganizationID: orgB, UserID: employeeID("dave")},
+        },
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := os.WriteFile(path, data, 0o644); err != nil {
+        t.Fatal(err)
+    }
+    repo, err := storage.New(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() { repo.Close() })
+
+    clock := &fakeClock{now: time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)}
+    f := &fixture{t: t, path: path, repo: repo, clock: clock}
+    f.tenders = NewTenderService(repo, repo, repo)
+    f.tenders.clock = clock
+    f.bids = NewBidService(repo, repo, repo, repo, repo, repo, repo)
+    f.bids.clock = clock
+    blobs, err := blob.NewFS(t.TempDir())
+    if err != nil {
+        t.Fatal(err)
+    }
+    f.attachments = NewAttachmentService(repo, repo, repo, blobs, repo)
+    f.attachments.clock = clock
+    f.lots = NewLotService(repo, repo, repo)
+    f.lots.clock = clock
+    f.questions = NewQuestionService(repo, repo, repo)
+    f.questions.clock = clock
+    f.scheduler = NewScheduler(repo, repo, clock)
+    f.audit = NewAuditService(repo, repo, repo)
+    return f
+}
+
+// must fails the test on err.
+func (f *fixture) must(err error) {
+    f.t.Helper()
+    if err != nil {
+        f.t.Fatal(err)
+    }
+}
+
+// tender creates a tender of orgA as alice and publishes it.
+func (f *fixture) tender(deadlines Deadlines, sealed bool) model.Tender {
+    f.t.Helper()
+    t, err := f.tenders.Create("Delivery", "d", "Delivery", orgA, "alice", nil, deadlines, sealed, nil, nil, nil)
+    f.must(err)
+    t, err = f.tenders.UpdateStatus(t.ID, model.TenderPublished, "alice", AnyVersion)
+    f.must(err)
+    return t
+}
+
+// bid creates a bid of dave on tenderID and publishes it.
+func (f *fixture) bid(tenderID string, price *model.Money) model.Bid {
+    f.t.Helper()
+    b, err := f.bids.Create("Offer", "o", tenderID, model.AuthorUser, employeeID("dave"), price, nil)
+    f.must(err)
+    b, err = f.bids.UpdateStatus(b.ID, model.BidPublished, "dave", AnyVersion)
+    f.must(err)
+    return b
+}
+
+// at returns a pointer to the fixture's time moved by d.
+func (f *fixture) at(d time.Duration) *time.Time {
+    t := f.clock.now.Add(d)
+    return &t
+}
+
+// wantErr fails the test unless err is target.
+func wantErr(t *testing.T, err, target error) {
+    t.Helper()
+    if !errors.Is(err, target) {
+        t.Fatalf("err = %v, want %v", err, target)
+    }
+}
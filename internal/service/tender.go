@@ -1,9 +1,7 @@
 package service
 
 import (
-    "errors"
-    "sort"
-    "time"
+    "fmt"
 
     "github.com/google/uuid"
 
@@ -12,25 +10,92 @@ import (
 )
 
 type TenderService struct {
-    repo *storage.Storage
+    repo     storage.TenderRepository
+    versions storage.VersionRepository
+    audit    auditor
+    access   access
+    clock    Clock
 }
 
-func NewTenderService(r *storage.Storage) *TenderService {
-    return &TenderService{repo: r}
+func NewTenderService(r storage.TenderRepository, versions storage.VersionRepository, users storage.UserRepository) *TenderService {
+    return &TenderService{repo: r, versions: versions, access: access{users: users}, clock: SystemClock}
 }
 
-func (s *TenderService) List(serviceTypes []string) []model.Tender {
-    res := make([]model.Tender, 0)
-    for _, t := range s.repo.Data.Tenders {
-        if len(serviceTypes) == 0 || contains(serviceTypes, t.ServiceType) {
-            res = append(res, t)
+// WithRequestID returns a copy of s that records its changes in the audit
+// log as made by the request id.
+func (s *TenderService) WithRequestID(id string) *TenderService {
+    c := *s
+    c.audit.requestID = id
+    return &c
+}
+
+// List returns a page of tenders of the given service types visible to
+// username: published tenders plus, for responsibles, every tender of their
+// organizations. An empty username sees published tenders only. The second
+// result is the cursor of the next page.
+func (s *TenderService) List(serviceTypes []string, username string, p Page) ([]model.Tender, string, error) {
+    var orgs []string
+    if username != "" {
+        user, err := s.access.employee(username)
+        if err != nil {
+            return nil, "", err
+        }
+        if orgs, err = s.access.organizations(user); err != nil {
+            return nil, "", err
+        }
+    }
+    res, err := s.repo.ListTenders(storage.TenderFilter{
+        ServiceTypes: serviceTypes,
+        Statuses:     []string{model.TenderPublished},
+    })
+    if err != nil {
+        return nil, "", err
+    }
+    if len(orgs) > 0 {
+        own, err := s.repo.ListTenders(storage.TenderFilter{ServiceTypes: serviceTypes, OrganizationIDs: orgs})
+        if err != nil {
+            return nil, "", err
+        }
+        for _, t := range own {
+            // Published ones are already in res.
+            if t.Status != model.TenderPublished {
+                res = append(res, t)
+            }
         }
     }
-    sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
-    return res
+    return paginate(res, p, tenderKey, false)
+}
+
+// Get returns a tender visible to username. Tenders that are not published
+// are only visible to responsibles of the owning organization.
+func (s *TenderService) Get(id, username string) (model.Tender, error) {
+    return visibleTender(s.repo, s.access, id, username)
+}
+
+// visibleTender implements TenderService.Get.
+func visibleTender(repo storage.TenderRepository, a access, id, username string) (model.Tender, error) {
+    tender, err := getTender(repo, id)
+    if err != nil {
+        return model.Tender{}, err
+    }
+    if tender.Status == model.TenderPublished {
+        return tender, nil
+    }
+    if _, err := a.responsible(username, tender.OrganizationID); err != nil {
+        return model.Tender{}, err
+    }
+    return tender, nil
 }
 
-func (s *TenderService) Create(name, desc, serviceType, orgID, username string) (model.Tender, error) {
+// Create adds a tender in status Created. The optional budget caps the
+// prices of its bids. The bids on a sealed tender stay hidden until it is
+// opened or its submission deadline passes. A tender with an auction takes
+// its submission deadline from the auction's end. Bids are evaluated by the
+// optional criteria. The tender may be split into lots right away.
+func (s *TenderService) Create(name, desc, serviceType, orgID, username string, budget *model.Money, deadlines Deadlines, sealed bool, auction *model.Auction, criteria []model.Criterion, lots []model.Lot) (model.Tender, error) {
+    if _, err := s.access.responsible(username, orgID); err != nil {
+        return model.Tender{}, err
+    }
     t := model.Tender{
         ID:              uuid.New().String(),
         Name:            name,
@@ -38,111 +103,263 @@ func (s *TenderService) Create(name, desc, serviceType, orgID, username string)
         ServiceType:     serviceType,
         OrganizationID:  orgID,
         CreatorUsername: username,
-        Status:          "Created",
+        Status:          model.TenderCreated,
+        Budget:          budget,
+        Sealed:          sealed,
+        Criteria:        criteria,
         Version:         1,
-        CreatedAt:       time.Now().UTC(),
+        CreatedAt:       s.clock.Now(),
+        UpdatedBy:       username,
     }
-    if err := s.repo.AddTender(t); err != nil {
+    t.UpdatedAt = t.CreatedAt
+    if err := checkCriteria(criteria, budget); err != nil {
+        return model.Tender{}, err
+    }
+    if auction != nil {
+        if sealed {
+            return model.Tender{}, fmt.Errorf("%w: an auction cannot be sealed", ErrInvalidAuction)
+        }
+        if deadlines.Submission != nil {
+            return model.Tender{}, fmt.Errorf("%w: an auction ends at its own end time", ErrInvalidDeadline)
+        }
+        a, err := newAuction(*auction, t.Budget, t.CreatedAt)
+        if err != nil {
+            return model.Tender{}, err
+        }
+        end := a.EndsAt
+        t.Auction = a
+        t.SubmissionDeadline = &end
+    }
+    if err := setDeadlines(&t, deadlines, t.CreatedAt); err != nil {
+        return model.Tender{}, err
+    }
+    for _, l := range lots {
+        t.Lots = append(t.Lots, newLot(l, username, t.CreatedAt))
+    }
+    if err := checkLots(t); err != nil {
+        return model.Tender{}, err
+    }
+    if err := s.repo.AddTender(t, s.audit.tender(model.AuditTenderCreate, username, 0, t)); err != nil {
         return model.Tender{}, err
     }
     return t, nil
 }
 
-func (s *TenderService) UserTenders(username string) []model.Tender {
-    res := make([]model.Tender, 0)
-    for _, t := range s.repo.Data.Tenders {
-        if t.CreatorUsername == username {
-            res = append(res, t)
-        }
+func (s *TenderService) UserTenders(username string, p Page) ([]model.Tender, string, error) {
+    if _, err := s.access.employee(username); err != nil {
+        return nil, "", err
+    }
+    res, err := s.repo.TendersByCreator(username)
+    if err != nil {
+        return nil, "", err
     }
-    sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
-    return res
+    return paginate(res, p, tenderKey, false)
+}
+
+// UpdateStatus moves the tender along its lifecycle. ifMatch is the version
+// the caller expects, or AnyVersion.
+func (s *TenderService) UpdateStatus(id, status, username string, ifMatch int) (model.Tender, error) {
+    user, err := s.access.employee(username)
+    if err != nil {
+        return model.Tender{}, err
+    }
+    return s.audit.updateTender(s.repo, model.AuditTenderStatus, user.Username, id, ifMatch, func(tender *model.Tender) error {
+        if err := s.access.check(user, tender.OrganizationID); err != nil {
+            return err
+        }
+        if err := checkTransition("tender", tenderTransitions, tender.Status, status); err != nil {
+            return err
+        }
+        if status == model.TenderPublished {
+            if err := checkSubmission(*tender, s.clock.Now(), "publish the tender"); err != nil {
+                return err
+            }
+        }
+        tender.Status = status
+        bumpTender(tender, user.Username, s.clock.Now())
+        return nil
+    })
 }
 
-func (s *TenderService) UpdateStatus(id, status string) (model.Tender, error) {
-    tender, ok := s.repo.GetTender(id)
-    if !ok {
-        return model.Tender{}, errors.New("not found")
+// Edit changes the given fields of a tender; nil ones are left as they are,
+// and empty criteria remove them. A new budget only applies to prices
+// offered from then on.
+func (s *TenderService) Edit(id, username string, ifMatch int, name, desc, serviceType *string, budget *model.Money, deadlines Deadlines, criteria []model.Criterion) (model.Tender, error) {
+    user, err := s.access.employee(username)
+    if err != nil {
+        return model.Tender{}, err
     }
-    tender.History = append(tender.History, model.TenderVersion{
-        Name:        tender.Name,
-        Description: tender.Description,
-        ServiceType: tender.ServiceType,
-        Status:      tender.Status,
-        Version:     tender.Version,
-        CreatedAt:   tender.CreatedAt,
+    return s.audit.updateTender(s.repo, model.AuditTenderEdit, user.Username, id, ifMatch, func(tender *model.Tender) error {
+        if err := s.access.check(user, tender.OrganizationID); err != nil {
+            return err
+        }
+        if name != nil {
+            tender.Name = *name
+        }
+        if desc != nil {
+            tender.Description = *desc
+        }
+        if serviceType != nil {
+            tender.ServiceType = *serviceType
+        }
+        if budget != nil {
+            if tender.Auction != nil && budget.Currency != tender.Auction.Step.Currency {
+                return fmt.Errorf("%w: the auction is in %s", ErrCurrencyMismatch, tender.Auction.Step.Currency)
+            }
+            tender.Budget = budget
+        }
+        if criteria != nil {
+            tender.Criteria = nil
+            if len(criteria) > 0 {
+                tender.Criteria = criteria
+            }
+        }
+        if err := checkCriteria(tender.Criteria, tender.Budget); err != nil {
+            return err
+        }
+        if err := checkLots(*tender); err != nil {
+            return err
+        }
+        if tender.Auction != nil && deadlines.Submission != nil {
+            return fmt.Errorf("%w: an auction ends at its own end time", ErrInvalidDeadline)
+        }
+        now := s.clock.Now()
+        if err := setDeadlines(tender, deadlines, now); err != nil {
+            return err
+        }
+        bumpTender(tender, user.Username, now)
+        return nil
     })
-    tender.Status = status
-    tender.Version++
-    if err := s.repo.UpdateTender(tender); err != nil {
+}
+
+// Open reveals the bids of a sealed tender ahead of its submission
+// deadline. Opening is recorded as a new version of the tender, naming who
+// opened it and when; opening it again changes nothing.
+func (s *TenderService) Open(id, username string, ifMatch int) (model.Tender, error) {
+    user, err := s.access.employee(username)
+    if err != nil {
         return model.Tender{}, err
     }
-    return tender, nil
+    return s.audit.updateTender(s.repo, model.AuditTenderOpen, user.Username, id, ifMatch, func(tender *model.Tender) error {
+        if err := s.access.check(user, tender.OrganizationID); err != nil {
+            return err
+        }
+        if !tender.Sealed {
+            return ErrNotSealed
+        }
+        if tender.OpenedAt != nil {
+            return errUnchanged
+        }
+        now := s.clock.Now()
+        tender.OpenedBy = user.Username
+        tender.OpenedAt = &now
+        bumpTender(tender, user.Username, now)
+        return nil
+    })
 }
 
-func (s *TenderService) Edit(id string, name, desc, serviceType *string) (model.Tender, error) {
-    tender, ok := s.repo.GetTender(id)
-    if !ok {
-        return model.Tender{}, errors.New("not found")
+func (s *TenderService) Rollback(id string, ver int, username string, ifMatch int) (model.Tender, error) {
+    user, err := s.access.employee(username)
+    if err != nil {
+        return model.Tender{}, err
     }
-    tender.History = append(tender.History, model.TenderVersion{
-        Name:        tender.Name,
-        Description: tender.Description,
-        ServiceType: tender.ServiceType,
-        Status:      tender.Status,
-        Version:     tender.Version,
-        CreatedAt:   tender.CreatedAt,
+    return s.audit.updateTender(s.repo, model.AuditTenderRollback, user.Username, id, ifMatch, func(tender *model.Tender) error {
+        if err := s.access.check(user, tender.OrganizationID); err != nil {
+            return err
+        }
+        versions, err := s.tenderVersions(*tender)
+        if err != nil {
+            return err
+        }
+        snap, err := findVersion(versions, ver, func(v model.TenderVersion) int { return v.Version })
+        if err != nil {
+            return err
+        }
+        tender.Name = snap.Name
+        tender.Description = snap.Description
+        tender.ServiceType = snap.ServiceType
+        tender.Attachments = snap.Attachments
+        // Status is owned by the lifecycle and is not rolled back, nor are
+        // deadlines, which may have passed since.
+        bumpTender(tender, user.Username, s.clock.Now())
+        return nil
     })
-    if name != nil {
-        tender.Name = *name
+}
+
+// Versions returns a page of every version of a tender visible to username,
+// oldest first; the last one is the current state.
+func (s *TenderService) Versions(id, username string, p Page) ([]model.TenderVersion, string, error) {
+    tender, err := s.Get(id, username)
+    if err != nil {
+        return nil, "", err
     }
-    if desc != nil {
-        tender.Description = *desc
+    versions, err := s.tenderVersions(tender)
+    if err != nil {
+        return nil, "", err
     }
-    if serviceType != nil {
-        tender.ServiceType = *serviceType
+    return paginate(versions, p, func(v model.TenderVersion) sortKey { return versionKey(tender.ID, v.Version) }, false)
+}
+
+// Version returns version ver of a tender visible to username.
+func (s *TenderService) Version(id string, ver int, username string) (model.TenderVersion, error) {
+    tender, err := s.Get(id, username)
+    if err != nil {
+        return model.TenderVersion{}, err
     }
-    tender.Version++
-    if err := s.repo.UpdateTender(tender); err != nil {
-        return model.Tender{}, err
+    versions, err := s.tenderVersions(tender)
+    if err != nil {
+        return model.TenderVersion{}, err
     }
-    return tender, nil
+    return findVersion(versions, ver, func(v model.TenderVersion) int { return v.Version })
 }
 
-func (s *TenderService) Rollback(id string, ver int) (model.Tender, error) {
-    tender, ok := s.repo.GetTender(id)
-    if !ok {
-        return model.Tender{}, errors.New("not found")
+// Diff lists the fields that differ between versions from and to of a
+// tender visible to username.
+func (s *TenderService) Diff(id string, from, to int, username string) (model.VersionDiff, error) {
+    tender, err := s.Get(id, username)
+    if err != nil {
+        return model.VersionDiff{}, err
     }
-    if ver < 1 || ver > len(tender.History) {
-        return model.Tender{}, errors.New("version not found")
+    versions, err := s.tenderVersions(tender)
+    if err != nil {
+        return model.VersionDiff{}, err
     }
-    snap := tender.History[ver-1]
-    tender.History = append(tender.History, model.TenderVersion{
-        Name:        tender.Name,
-        Description: tender.Description,
-        ServiceType: tender.ServiceType,
-        Status:      tender.Status,
-        Version:     tender.Version,
-        CreatedAt:   tender.CreatedAt,
-    })
-    tender.Name = snap.Name
-    tender.Description = snap.Description
-    tender.ServiceType = snap.ServiceType
-    tender.Status = snap.Status
-    tender.Version++
-    if err := s.repo.UpdateTender(tender); err != nil {
-        return model.Tender{}, err
+    var revs []revision
+    for _, v := range versions {
+        revs = append(revs, tenderRevision(v))
     }
-    return tender, nil
+    return diff(tenderFields, revs, from, to)
+}
+
+// getTender loads a tender, reporting a missing one as NotFoundError.
+func getTender(repo storage.TenderRepository, id string) (model.Tender, error) {
+    t, err := repo.GetTender(id)
+    return t, notFound(err, "tender", id)
 }
 
-func contains(arr []string, s string) bool {
-    for _, a := range arr {
-        if a == s {
-            return true
+// tenderVersions returns every retained version of t, oldest first,
+// ending with its current state.
+func (s *TenderService) tenderVersions(t model.Tender) ([]model.TenderVersion, error) {
+    res, err := s.versions.TenderVersions(t.ID)
+    if err != nil {
+        return nil, err
+    }
+    return append(res, t.Snapshot()), nil
+}
+
+// WithHistory returns ts with their archived versions filled in.
+func (s *TenderService) WithHistory(ts ...model.Tender) ([]model.Tender, error) {
+    for i := range ts {
+        h, err := s.versions.TenderVersions(ts[i].ID)
+        if err != nil {
+            return nil, err
         }
+        ts[i].History = h
     }
-    return false
+    return ts, nil
 }
 
+// tenderKey orders tenders alphabetically by name.
+func tenderKey(t model.Tender) sortKey {
+    return sortKey{Primary: t.Name, ID: t.ID}
+}
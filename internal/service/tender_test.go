@@ -0,0 +1,120 @@
+package service
+
+import (
+    "testing"
+    "time"
+
+    "tender/internal/model"
+)
+
+// rename edits the name of tender id as username.
+func (f *fixture) rename(id, username, name string) model.Tender {
+    f.t.Helper()
+    t, err := f.tenders.Edit(id, username, AnyVersion, &name, nil, nil, nil, Deadlines{}, nil)
+    f.must(err)
+    return t
+}
+
+func TestTenderRollbackAfterRollback(t *testing.T) {
+    f := newFixture(t)
+    tender, err := f.tenders.Create("v1", "d", "Delivery", orgA, "alice", nil, Deadlines{}, false, nil, nil, nil)
+    f.must(err)
+    f.rename(tender.ID, "alice", "v2")
+    f.rename(tender.ID, "alice", "v3")
+
+    // Each rollback is a new version, so from the first one on version
+    // numbers run ahead of the names they carry.
+    steps := []struct {
+        to       int
+        wantName string
+    }{
+        {1, "v1"},
+        {3, "v3"},
+        {4, "v1"},
+        {2, "v2"},
+    }
+    for i, st := range steps {
+        got, err := f.tenders.Rollback(tender.ID, st.to, "alice", AnyVersion)
+        f.must(err)
+        if want := 4 + i; got.Version != want {
+            t.Fatalf("rollback to %d: version %d, want %d", st.to, got.Version, want)
+        }
+        if got.Name != st.wantName {
+            t.Fatalf("rollback to %d: name %q, want %q", st.to, got.Name, st.wantName)
+        }
+    }
+
+    versions, _, err := f.tenders.Versions(tender.ID, "alice", Page{Limit: 50})
+    f.must(err)
+    want := []string{"v1", "v2", "v3", "v1", "v3", "v1", "v2"}
+    if len(versions) != len(want) {
+        t.Fatalf("got %d versions, want %d", len(versions), len(want))
+    }
+    for i, v := range versions {
+        if v.Version != i+1 || v.Name != want[i] {
+            t.Errorf("versions[%d] = %d %q, want %d %q", i, v.Version, v.Name, i+1, want[i])
+        }
+    }
+}
+
+func TestTenderRollbackToPrunedVersion(t *testing.T) {
+    f := newFixture(t)
+    tender, err := f.tenders.Create("v1", "d", "Delivery", orgA, "alice", nil, Deadlines{}, false, nil, nil, nil)
+    f.must(err)
+    f.rename(tender.ID, "alice", "v2")
+    f.rename(tender.ID, "alice", "v3")
+    f.rename(tender.ID, "alice", "v4")
+
+    // Keeping one archived version drops versions 1 and 2.
+    n, err := Retention{KeepLast: 1}.Prune(f.repo, f.clock.Now())
+    f.must(err)
+    if n != 2 {
+        t.Fatalf("pruned %d versions, want 2", n)
+    }
+    _, err = f.tenders.Rollback(tender.ID, 1, "alice", AnyVersion)
+    wantErr(t, err, ErrNotFound)
+    _, err = f.tenders.Version(tender.ID, 2, "alice")
+    wantErr(t, err, ErrNotFound)
+
+    // The retained version is still found by its number although the ones
+    // before it are gone.
+    got, err := f.tenders.Rollback(tender.ID, 3, "alice", AnyVersion)
+    f.must(err)
+    if got.Version != 5 || got.Name != "v3" {
+        t.Fatalf("got version %d %q, want 5 %q", got.Version, got.Name, "v3")
+    }
+}
+
+func TestTenderVersionsRecordActorAndTime(t *testing.T) {
+    f := newFixture(t)
+    created := f.clock.Now()
+    tender, err := f.tenders.Create("v1", "d", "Delivery", orgA, "alice", nil, Deadlines{}, false, nil, nil, nil)
+    f.must(err)
+    f.clock.advance(time.Hour)
+    f.rename(tender.ID, "bob", "v2")
+    f.clock.advance(time.Hour)
+    _, err = f.tenders.Rollback(tender.ID, 1, "bob", AnyVersion)
+    f.must(err)
+
+    want := []struct {
+        by string
+        at time.Time
+    }{
+        {"alice", created},
+        {"bob", created.Add(time.Hour)},
+        {"bob", created.Add(2 * time.Hour)},
+    }
+    for i, w := range want {
+        v, err := f.tenders.Version(tender.ID, i+1, "alice")
+        f.must(err)
+        if v.Version != i+1 || v.UpdatedBy != w.by || !v.UpdatedAt.Equal(w.at) {
+            t.Errorf("version %d = %d by %q at %v, want by %q at %v", i+1, v.Version, v.UpdatedBy, v.UpdatedAt, w.by, w.at)
+        }
+    }
+    // Version 3 is the rollback, made of version 1's content.
+    v, err := f.tenders.Version(tender.ID, 3, "alice")
+    f.must(err)
+    if v.Name != "v1" {
+        t.Errorf("version 3 name %q, want %q", v.Name, "v1")
+    }
+}
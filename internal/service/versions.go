@@ -0,0 +1,159 @@
+package service
+
+import (
+    "fmt"
+    "strings"
+    "time"
+
+    "tender/internal/model"
+)
+
+// bumpTender makes t its next version, produced by actor at now. The
+// storage archives the replaced version when t is saved.
+func bumpTender(t *model.Tender, actor string, now time.Time) {
+    t.Version++
+    t.UpdatedBy = actor
+    t.UpdatedAt = now
+}
+
+// bumpBid is bumpTender for bids.
+func bumpBid(b *model.Bid, actor string, now time.Time) {
+    b.Version++
+    b.UpdatedBy = actor
+    b.UpdatedAt = now
+}
+
+// findVersion returns the version numbered ver among versions, which are
+// ordered oldest first. Numbers may skip, and data written before every
+// change bumped the version may hold one number twice; the latest snapshot
+// of it wins.
+func findVersion[T any](versions []T, ver int, number func(T) int) (T, error) {
+    for i := len(versions) - 1; i >= 0; i-- {
+        if number(versions[i]) == ver {
+            return versions[i], nil
+        }
+    }
+    var zero T
+    return zero, &NotFoundError{Entity: "version", ID: fmt.Sprint(ver)}
+}
+
+// revision is a version reduced to what diff compares: the values of the
+// entity's diffable fields, in a fixed order, plus who made it and when.
+type revision struct {
+    version   int
+    updatedBy string
+    updatedAt time.Time
+    values    []string
+}
+
+var tenderFields = []string{"name", "description", "serviceType", "status", "submissionDeadline", "decisionDeadline",
+    "openedBy", "openedAt", "budget", "bestPrice", "criteria", "lots", "attachments"}
+
+func tenderRevision(v model.TenderVersion) revision {
+    var best *model.Money
+    if v.Auction != nil {
+        best = v.Auction.BestPrice
+    }
+    return revision{v.Version, v.UpdatedBy, v.UpdatedAt, []string{v.Name, v.Description, v.ServiceType, v.Status,
+        formatTime(v.SubmissionDeadline), formatTime(v.DecisionDeadline), v.OpenedBy, formatTime(v.OpenedAt),
+        formatMoney(v.Budget), formatMoney(best), formatCriteria(v.Criteria), formatLots(v.Lots),
+        formatAttachments(v.Attachments)}}
+}
+
+// formatTime renders an optional time for diff, empty when unset.
+func formatTime(t *time.Time) string {
+    if t == nil {
+        return ""
+    }
+    return t.Format(time.RFC3339)
+}
+
+// formatCriteria renders criteria for diff as "name (kind, weight)" items.
+func formatCriteria(criteria []model.Criterion) string {
+    items := make([]string, len(criteria))
+    for i, c := range criteria {
+        items[i] = fmt.Sprintf("%s (%s, %d)", c.Name, c.Kind, c.Weight)
+    }
+    return strings.Join(items, ", ")
+}
+
+// formatLots renders lots for diff as "name (status)" items.
+func formatLots(lots []model.Lot) string {
+    items := make([]string, len(lots))
+    for i, l := range lots {
+        items[i] = fmt.Sprintf("%s (%s)", l.Name, l.Status)
+    }
+    return strings.Join(items, ", ")
+}
+
+// formatAttachments renders attachments for diff as "name (sha256)" items,
+// with the hash shortened.
+func formatAttachments(attachments []model.Attachment) string {
+    items := make([]string, len(attachments))
+    for i, a := range attachments {
+        items[i] = fmt.Sprintf("%s (%.12s)", a.Name, a.SHA256)
+    }
+    return strings.Join(items, ", ")
+}
+
+var bidFields = []string{"name", "description", "status", "decision", "feedback", "price", "attachments"}
+
+func bidRevision(v model.BidVersion) revision {
+    return revision{v.Version, v.UpdatedBy, v.UpdatedAt, []string{v.Name, v.Description, v.Status, v.Decision, v.Feedback,
+        formatMoney(v.Price), formatAttachments(v.Attachments)}}
+}
+
+// formatMoney renders an optional amount of money for diff, empty when
+// unset.
+func formatMoney(a *model.Money) string {
+    if a == nil {
+        return ""
+    }
+    return a.String()
+}
+
+// diff compares versions from and to (from <= to) of revs, which are
+// ordered by version. Each changed field is attributed to the last version
+// in (from, to] that changed it.
+func diff(fields []string, revs []revision, from, to int) (model.VersionDiff, error) {
+    index := func(ver int) (int, error) {
+        for i := len(revs) - 1; i >= 0; i-- {
+            if revs[i].version == ver {
+                return i, nil
+            }
+        }
+        return 0, &NotFoundError{Entity: "version", ID: fmt.Sprint(ver)}
+    }
+    lo, err := index(from)
+    if err != nil {
+        return model.VersionDiff{}, err
+    }
+    hi, err := index(to)
+    if err != nil {
+        return model.VersionDiff{}, err
+    }
+    res := model.VersionDiff{From: from, To: to, Changes: []model.FieldChange{}}
+    for k, field := range fields {
+        if revs[lo].values[k] == revs[hi].values[k] {
+            continue
+        }
+        last := hi
+        for last > lo+1 && revs[last].values[k] == revs[last-1].values[k] {
+            last--
+        }
+        res.Changes = append(res.Changes, model.FieldChange{
+            Field:     field,
+            From:      revs[lo].values[k],
+            To:        revs[hi].values[k],
+            Version:   revs[last].version,
+            UpdatedBy: revs[last].updatedBy,
+            UpdatedAt: revs[last].updatedAt,
+        })
+    }
+    return res, nil
+}
+
+// versionKey orders the versions of the entity id by number.
+func versionKey(id string, ver int) sortKey {
+    return sortKey{Primary: fmt.Sprintf("%010d", ver), ID: id}
+}
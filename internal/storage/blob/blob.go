@@ -0,0 +1,67 @@
+// Package blob stores the contents of attachments. Contents are addressed
+// by their SHA-256, so they are immutable and shared by every version of
+// every tender or bid that carries them; nothing is ever deleted.
+package blob
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+var (
+	// ErrNotFound is returned by Get for a key that holds no content.
+	ErrNotFound = errors.New("blob not found")
+	// ErrTooLarge is returned by Save for content over the size limit.
+	ErrTooLarge = errors.New("blob too large")
+)
+
+// Store is a backend for contents: the local file system (FS) or an
+// S3-compatible object store (S3).
+type Store interface {
+	// Put stores the size bytes read from r under key. sum is the hex
+	// SHA-256 of the content, which backends may use to verify it.
+	Put(key string, r io.Reader, size int64, sum string) error
+	// Get opens the content stored under key.
+	Get(key string) (io.ReadCloser, error)
+}
+
+// Info describes content saved with Save.
+type Info struct {
+	Key    string
+	Size   int64
+	SHA256 string
+}
+
+// Save stores the content read from r in s under its SHA-256 and returns
+// where. Content over max bytes fails with ErrTooLarge. The content is
+// spooled to a temporary file first, since the key is only known once it
+// has been read.
+func Save(s Store, r io.Reader, max int64) (Info, error) {
+	tmp, err := os.CreateTemp("", "blob-*")
+	if err != nil {
+		return Info{}, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), io.LimitReader(r, max+1))
+	if err != nil {
+		return Info{}, err
+	}
+	if size > max {
+		return Info{}, fmt.Errorf("%w: more than %d bytes", ErrTooLarge, max)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return Info{}, err
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	info := Info{Key: sum, Size: size, SHA256: sum}
+	if err := s.Put(info.Key, tmp, size, sum); err != nil {
+		return Info{}, err
+	}
+	return info, nil
+}
@@ -0,0 +1,145 @@
+package blob
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// emptySHA256 is the hex SHA-256 of an empty payload.
+const emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// S3Config locates a bucket of an S3-compatible service. Endpoint is the
+// service URL, such as https://s3.eu-central-1.amazonaws.com or
+// http://localhost:9000 for a local stand-in; Region defaults to us-east-1.
+type S3Config struct {
+	Endpoint        string
+	Bucket          string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// S3 keeps contents as objects of a bucket in an S3-compatible service. It
+// speaks the REST API directly, with path-style addressing and Signature
+// Version 4, which AWS and stand-ins such as MinIO all accept.
+type S3 struct {
+	endpoint *url.URL
+	cfg      S3Config
+	client   *http.Client
+}
+
+func NewS3(cfg S3Config) (*S3, error) {
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", cfg.Endpoint)
+	}
+	if cfg.Bucket == "" {
+		return nil, errors.New("S3 bucket is not set")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	return &S3{endpoint: u, cfg: cfg, client: &http.Client{Timeout: 5 * time.Minute}}, nil
+}
+
+func (s *S3) Put(key string, r io.Reader, size int64, sum string) error {
+	req, err := s.request(http.MethodPut, key, io.NopCloser(r), sum)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s.failure(req, resp)
+	}
+	return nil
+}
+
+func (s *S3) Get(key string) (io.ReadCloser, error) {
+	req, err := s.request(http.MethodGet, key, nil, emptySHA256)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrNotFound
+	default:
+		defer resp.Body.Close()
+		return nil, s.failure(req, resp)
+	}
+}
+
+// failure reports an unexpected response along with the start of the error
+// document S3 sends with it.
+func (s *S3) failure(req *http.Request, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("s3 %s %s: %s: %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(body)))
+}
+
+// request builds a signed request for the object key, whose payload has
+// the hex SHA-256 sum.
+func (s *S3) request(method, key string, body io.ReadCloser, sum string) (*http.Request, error) {
+	u := *s.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + s.cfg.Bucket + "/" + key
+	req, err := http.NewRequest(method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Body = body
+	s.sign(req, sum, time.Now().UTC())
+	return req, nil
+}
+
+// sign adds the Signature Version 4 authorization of req, made at now,
+// with the headers it covers: host, x-amz-content-sha256 and x-amz-date.
+func (s *S3) sign(req *http.Request, sum string, now time.Time) {
+	date, stamp := now.Format("20060102"), now.Format("20060102T150405Z")
+	req.Header.Set("X-Amz-Content-Sha256", sum)
+	req.Header.Set("X-Amz-Date", stamp)
+	const signed = "host;x-amz-content-sha256;x-amz-date"
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"host:" + req.URL.Host,
+		"x-amz-content-sha256:" + sum,
+		"x-amz-date:" + stamp,
+		"",
+		signed,
+		sum,
+	}, "\n")
+	scope := date + "/" + s.cfg.Region + "/s3/aws4_request"
+	hash := sha256.Sum256([]byte(canonical))
+	toSign := "AWS4-HMAC-SHA256\n" + stamp + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+	key := []byte("AWS4" + s.cfg.SecretAccessKey)
+	for _, part := range []string{date, s.cfg.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.AccessKeyID, scope, signed, hex.EncodeToString(hmacSHA256(key, toSign))))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	m := hmac.New(sha256.New, key)
+	m.Write([]byte(data))
+	return m.Sum(nil)
+}
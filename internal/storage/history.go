@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"slices"
+	"time"
+
+	"tender/internal/model"
+)
+
+func (m *Memory) TenderVersions(tenderID string) ([]model.TenderVersion, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return slices.Clone(m.data.TenderVersions[tenderID]), nil
+}
+
+func (m *Memory) BidVersions(bidID string) ([]model.BidVersion, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return slices.Clone(m.data.BidVersions[bidID]), nil
+}
+
+func (m *Memory) PruneVersions(keepLast int, cutoff time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// Count first so that a prune with nothing to do is not logged.
+	n := 0
+	for _, vs := range m.data.TenderVersions {
+		n += len(vs) - len(retain(vs, keepLast, cutoff, func(v model.TenderVersion) time.Time { return v.UpdatedAt }))
+	}
+	for _, vs := range m.data.BidVersions {
+		n += len(vs) - len(retain(vs, keepLast, cutoff, func(v model.BidVersion) time.Time { return v.UpdatedAt }))
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	if err := m.commit(op{Kind: opPruneVersions, Prune: &prune{KeepLast: keepLast, Cutoff: cutoff}}); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+func (d *Data) pruneVersions(keepLast int, cutoff time.Time) {
+	for id, vs := range d.TenderVersions {
+		d.TenderVersions[id] = retain(vs, keepLast, cutoff, func(v model.TenderVersion) time.Time { return v.UpdatedAt })
+	}
+	for id, vs := range d.BidVersions {
+		d.BidVersions[id] = retain(vs, keepLast, cutoff, func(v model.BidVersion) time.Time { return v.UpdatedAt })
+	}
+}
+
+// retain returns the tail of versions, which are ordered oldest first, that
+// is within keepLast entries and produced at or after cutoff.
+func retain[T any](versions []T, keepLast int, cutoff time.Time, at func(T) time.Time) []T {
+	start := 0
+	if keepLast > 0 && len(versions) > keepLast {
+		start = len(versions) - keepLast
+	}
+	if !cutoff.IsZero() {
+		for start < len(versions) && at(versions[start]).Before(cutoff) {
+			start++
+		}
+	}
+	if start == 0 {
+		return versions
+	}
+	return slices.Clone(versions[start:])
+}
+
+// migrateHistory moves version history embedded in tenders and bids, as
+// data files written before the history store kept it, into the store.
+func (d *Data) migrateHistory() {
+	if d.TenderVersions == nil {
+		d.TenderVersions = map[string][]model.TenderVersion{}
+	}
+	if d.BidVersions == nil {
+		d.BidVersions = map[string][]model.BidVersion{}
+	}
+	for i := range d.Tenders {
+		t := &d.Tenders[i]
+		if len(t.History) > 0 && len(d.TenderVersions[t.ID]) == 0 {
+			d.TenderVersions[t.ID] = t.History
+		}
+		t.History = nil
+	}
+	for i := range d.Bids {
+		b := &d.Bids[i]
+		if len(b.History) > 0 && len(d.BidVersions[b.ID]) == 0 {
+			d.BidVersions[b.ID] = b.History
+		}
+		b.History = nil
+	}
+}
@@ -0,0 +1,263 @@
+package storage
+
+import (
+	"slices"
+
+	"tender/internal/model"
+)
+
+// positions is a set of positions in one of the Data slices. Entities are
+// never deleted, so a position stays valid for the life of the store.
+type positions map[int]struct{}
+
+// postings maps an indexed value to the positions of the entities with it.
+type postings map[string]positions
+
+func (p postings) add(key string, i int) {
+	set, ok := p[key]
+	if !ok {
+		set = positions{}
+		p[key] = set
+	}
+	set[i] = struct{}{}
+}
+
+func (p postings) remove(key string, i int) {
+	delete(p[key], i)
+	if len(p[key]) == 0 {
+		delete(p, key)
+	}
+}
+
+// move reindexes position i after its value changed from old to key.
+func (p postings) move(old, key string, i int) {
+	if old != key {
+		p.remove(old, i)
+		p.add(key, i)
+	}
+}
+
+// count returns how many positions union(keys) would return, counting
+// positions under several keys more than once.
+func (p postings) count(keys []string) int {
+	n := 0
+	for _, k := range keys {
+		n += len(p[k])
+	}
+	return n
+}
+
+// union returns the positions indexed under any of keys in insertion order.
+func (p postings) union(keys []string) []int {
+	var res []int
+	for _, k := range keys {
+		for i := range p[k] {
+			res = append(res, i)
+		}
+	}
+	slices.Sort(res)
+	return slices.Compact(res)
+}
+
+type voteKey struct{ bidID, userID string }
+
+type responsibleKey struct{ orgID, userID string }
+
+// indexes are the secondary indexes Memory keeps over Data. They are updated
+// together with the data in Memory.apply.
+type indexes struct {
+	tenderByID           map[string]int
+	tendersByCreator     postings
+	tendersByOrg         postings
+	tendersByServiceType postings
+	tendersByStatus      postings
+
+	bidByID      map[string]int
+	bidsByTender postings
+	bidsByAuthor postings
+
+	decisionsByBid postings
+	votes          map[voteKey]bool
+
+	evaluationsByTender postings
+	evaluationByVoter   map[voteKey]int
+
+	questionByID      map[string]int
+	questionsByTender postings
+
+	reviewsByAuthor postings
+
+	auditByTender postings
+
+	employeeByUsername map[string]int
+	employeeByID       map[string]int
+	organizationByID   map[string]int
+	responsiblesByOrg  postings
+	responsiblesByUser postings
+	responsible        map[responsibleKey]bool
+}
+
+// newIndexes indexes everything in d.
+func newIndexes(d *Data) indexes {
+	x := indexes{
+		tenderByID:           map[string]int{},
+		tendersByCreator:     postings{},
+		tendersByOrg:         postings{},
+		tendersByServiceType: postings{},
+		tendersByStatus:      postings{},
+		bidByID:              map[string]int{},
+		bidsByTender:         postings{},
+		bidsByAuthor:         postings{},
+		decisionsByBid:       postings{},
+		votes:                map[voteKey]bool{},
+		evaluationsByTender:  postings{},
+		evaluationByVoter:    map[voteKey]int{},
+		questionByID:         map[string]int{},
+		questionsByTender:    postings{},
+		reviewsByAuthor:      postings{},
+		auditByTender:        postings{},
+		employeeByUsername:   map[string]int{},
+		employeeByID:         map[string]int{},
+		organizationByID:     map[string]int{},
+		responsiblesByOrg:    postings{},
+		responsiblesByUser:   postings{},
+		responsible:          map[responsibleKey]bool{},
+	}
+	for i, t := range d.Tenders {
+		x.addTender(t, i)
+	}
+	for i, b := range d.Bids {
+		x.addBid(b, i)
+	}
+	for i, dec := range d.Decisions {
+		x.addDecision(dec, i)
+	}
+	for i, e := range d.Evaluations {
+		x.addEvaluation(e, i)
+	}
+	for i, q := range d.Questions {
+		x.addQuestion(q, i)
+	}
+	for i, r := range d.Reviews {
+		x.addReview(r, i)
+	}
+	for i, e := range d.Audit {
+		x.auditByTender.add(e.TenderID, i)
+	}
+	for i, e := range d.Employees {
+		x.addEmployee(e, i)
+	}
+	for i, o := range d.Organizations {
+		x.organizationByID[o.ID] = i
+	}
+	for i, r := range d.Responsibles {
+		x.addResponsible(r, i)
+	}
+	return x
+}
+
+func (x *indexes) addTender(t model.Tender, i int) {
+	x.tenderByID[t.ID] = i
+	x.tendersByCreator.add(t.CreatorUsername, i)
+	x.tendersByOrg.add(t.OrganizationID, i)
+	x.tendersByServiceType.add(t.ServiceType, i)
+	x.tendersByStatus.add(t.Status, i)
+}
+
+func (x *indexes) updateTender(old, t model.Tender, i int) {
+	x.tendersByCreator.move(old.CreatorUsername, t.CreatorUsername, i)
+	x.tendersByOrg.move(old.OrganizationID, t.OrganizationID, i)
+	x.tendersByServiceType.move(old.ServiceType, t.ServiceType, i)
+	x.tendersByStatus.move(old.Status, t.Status, i)
+}
+
+func (x *indexes) addBid(b model.Bid, i int) {
+	x.bidByID[b.ID] = i
+	x.bidsByTender.add(b.TenderID, i)
+	x.bidsByAuthor.add(b.AuthorID, i)
+}
+
+func (x *indexes) updateBid(old, b model.Bid, i int) {
+	x.bidsByTender.move(old.TenderID, b.TenderID, i)
+	x.bidsByAuthor.move(old.AuthorID, b.AuthorID, i)
+}
+
+func (x *indexes) addDecision(d model.BidDecision, i int) {
+	x.decisionsByBid.add(d.BidID, i)
+	x.votes[voteKey{d.BidID, d.UserID}] = true
+}
+
+func (x *indexes) addEvaluation(e model.BidEvaluation, i int) {
+	x.evaluationsByTender.add(e.TenderID, i)
+	x.evaluationByVoter[voteKey{e.BidID, e.EvaluatorID}] = i
+}
+
+func (x *indexes) addQuestion(q model.Question, i int) {
+	x.questionByID[q.ID] = i
+	x.questionsByTender.add(q.TenderID, i)
+}
+
+func (x *indexes) addReview(r model.BidReview, i int) {
+	x.reviewsByAuthor.add(r.AuthorID, i)
+}
+
+func (x *indexes) addEmployee(e model.Employee, i int) {
+	x.employeeByUsername[e.Username] = i
+	x.employeeByID[e.ID] = i
+}
+
+func (x *indexes) addResponsible(r model.OrganizationResponsible, i int) {
+	x.responsiblesByOrg.add(r.OrganizationID, i)
+	x.responsiblesByUser.add(r.UserID, i)
+	x.responsible[responsibleKey{r.OrganizationID, r.UserID}] = true
+}
+
+// tenderCandidates returns the positions of the tenders that may match f,
+// taken from the most selective index among the fields f sets, or nil and
+// false when f sets no indexed field.
+func (x *indexes) tenderCandidates(f TenderFilter) ([]int, bool) {
+	type choice struct {
+		p    postings
+		keys []string
+	}
+	var choices []choice
+	if len(f.ServiceTypes) > 0 {
+		choices = append(choices, choice{x.tendersByServiceType, f.ServiceTypes})
+	}
+	if len(f.Statuses) > 0 {
+		choices = append(choices, choice{x.tendersByStatus, f.Statuses})
+	}
+	if len(f.OrganizationIDs) > 0 {
+		choices = append(choices, choice{x.tendersByOrg, f.OrganizationIDs})
+	}
+	if f.CreatorUsername != "" {
+		choices = append(choices, choice{x.tendersByCreator, []string{f.CreatorUsername}})
+	}
+	if len(choices) == 0 {
+		return nil, false
+	}
+	best := slices.MinFunc(choices, func(a, b choice) int {
+		return a.p.count(a.keys) - b.p.count(b.keys)
+	})
+	return best.p.union(best.keys), true
+}
+
+// matchTender reports whether t satisfies every field f sets.
+func matchTender(f TenderFilter, t model.Tender) bool {
+	return (len(f.ServiceTypes) == 0 || slices.Contains(f.ServiceTypes, t.ServiceType)) &&
+		(len(f.Statuses) == 0 || slices.Contains(f.Statuses, t.Status)) &&
+		(len(f.OrganizationIDs) == 0 || slices.Contains(f.OrganizationIDs, t.OrganizationID)) &&
+		(f.CreatorUsername == "" || t.CreatorUsername == f.CreatorUsername) &&
+		(f.ExpiredBy.IsZero() || t.Expired(f.ExpiredBy, f.DecisionWindow))
+}
+
+// matchAudit reports whether e satisfies every field f sets.
+func matchAudit(f AuditFilter, e model.AuditEvent) bool {
+	return (f.Actor == "" || e.Actor == f.Actor) &&
+		(f.Action == "" || e.Action == f.Action) &&
+		(f.EntityType == "" || e.EntityType == f.EntityType) &&
+		(f.EntityID == "" || e.EntityID == f.EntityID) &&
+		(len(f.TenderIDs) == 0 || slices.Contains(f.TenderIDs, e.TenderID)) &&
+		(f.Since.IsZero() || !e.At.Before(f.Since)) &&
+		(f.Until.IsZero() || e.At.Before(f.Until))
+}
@@ -0,0 +1,362 @@
+package storage
+
+import (
+	"slices"
+	"sync"
+
+	"tender/internal/model"
+)
+
+// Memory is a Repository that keeps everything in process memory. It is the
+// backing store of the JSON file storage and can be used on its own in tests
+// or throwaway setups.
+type Memory struct {
+	mu   sync.RWMutex
+	data Data
+	idx  indexes
+
+	// persist, when set, is called with the lock held before every
+	// mutation is applied. An error aborts the mutation.
+	persist func(op) error
+}
+
+func NewMemory() *Memory {
+	m := &Memory{}
+	m.reset(Data{})
+	return m
+}
+
+// reset replaces the data and rebuilds the indexes over it.
+func (m *Memory) reset(d Data) {
+	m.data = d
+	m.data.migrateHistory()
+	m.idx = newIndexes(&m.data)
+}
+
+// commit persists o and applies it to the in-memory data.
+func (m *Memory) commit(o op) error {
+	if m.persist != nil {
+		if err := m.persist(o); err != nil {
+			return err
+		}
+	}
+	m.apply(o)
+	return nil
+}
+
+func (m *Memory) AddTender(t model.Tender, e model.AuditEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.commit(op{Kind: opAddTender, Tender: &t, Audit: m.chain(e)})
+}
+
+func (m *Memory) UpdateTender(t model.Tender, expected int, e model.AuditEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i, ok := m.idx.tenderByID[t.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if m.data.Tenders[i].Version != expected {
+		return ErrConflict
+	}
+	return m.commit(op{Kind: opUpdateTender, Tender: &t, Audit: m.chain(e)})
+}
+
+func (m *Memory) GetTender(id string) (model.Tender, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	i, ok := m.idx.tenderByID[id]
+	if !ok {
+		return model.Tender{}, ErrNotFound
+	}
+	return m.data.Tenders[i], nil
+}
+
+// ListTenders scans only the candidates of the most selective index among
+// the fields f sets, and every tender when it sets no indexed field.
+func (m *Memory) ListTenders(f TenderFilter) ([]model.Tender, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	cands, indexed := m.idx.tenderCandidates(f)
+	if !indexed {
+		if f.ExpiredBy.IsZero() {
+			return slices.Clone(m.data.Tenders), nil
+		}
+		cands = make([]int, len(m.data.Tenders))
+		for i := range cands {
+			cands[i] = i
+		}
+	}
+	var res []model.Tender
+	for _, i := range cands {
+		if t := m.data.Tenders[i]; matchTender(f, t) {
+			res = append(res, t)
+		}
+	}
+	return res, nil
+}
+
+func (m *Memory) TendersByCreator(username string) ([]model.Tender, error) {
+	return m.ListTenders(TenderFilter{CreatorUsername: username})
+}
+
+func (m *Memory) AddBid(b model.Bid, e model.AuditEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.commit(op{Kind: opAddBid, Bid: &b, Audit: m.chain(e)})
+}
+
+func (m *Memory) UpdateBid(b model.Bid, expected int, e model.AuditEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.expectBid(b.ID, expected); err != nil {
+		return err
+	}
+	return m.commit(op{Kind: opUpdateBid, Bid: &b, Audit: m.chain(e)})
+}
+
+// expectBid checks that bid id is stored at version expected. The caller
+// holds the write lock.
+func (m *Memory) expectBid(id string, expected int) error {
+	i, ok := m.idx.bidByID[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if m.data.Bids[i].Version != expected {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (m *Memory) GetBid(id string) (model.Bid, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	i, ok := m.idx.bidByID[id]
+	if !ok {
+		return model.Bid{}, ErrNotFound
+	}
+	return m.data.Bids[i], nil
+}
+
+func (m *Memory) BidsByTender(tenderID string) ([]model.Bid, error) {
+	return m.bidsAt(m.idx.bidsByTender, tenderID), nil
+}
+
+func (m *Memory) BidsByAuthor(authorID string) ([]model.Bid, error) {
+	return m.bidsAt(m.idx.bidsByAuthor, authorID), nil
+}
+
+// bidsAt returns the bids indexed under key in p.
+func (m *Memory) bidsAt(p postings, key string) []model.Bid {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var res []model.Bid
+	for _, i := range p.union([]string{key}) {
+		res = append(res, m.data.Bids[i])
+	}
+	return res
+}
+
+func (m *Memory) AddDecision(d model.BidDecision, e model.AuditEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.idx.votes[voteKey{d.BidID, d.UserID}] {
+		return ErrAlreadyExists
+	}
+	return m.commit(op{Kind: opAddDecision, Decision: &d, Audit: m.chain(e)})
+}
+
+func (m *Memory) ListDecisions(bidID string) ([]model.BidDecision, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var res []model.BidDecision
+	for _, i := range m.idx.decisionsByBid.union([]string{bidID}) {
+		res = append(res, m.data.Decisions[i])
+	}
+	return res, nil
+}
+
+func (m *Memory) PutEvaluation(e model.BidEvaluation, event model.AuditEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.commit(op{Kind: opPutEvaluation, Evaluation: &e, Audit: m.chain(event)})
+}
+
+func (m *Memory) EvaluationsByTender(tenderID string) ([]model.BidEvaluation, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var res []model.BidEvaluation
+	for _, i := range m.idx.evaluationsByTender.union([]string{tenderID}) {
+		res = append(res, m.data.Evaluations[i])
+	}
+	return res, nil
+}
+
+func (m *Memory) AddQuestion(q model.Question, e model.AuditEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.commit(op{Kind: opAddQuestion, Question: &q, Audit: m.chain(e)})
+}
+
+func (m *Memory) UpdateQuestion(q model.Question, expected int, e model.AuditEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i, ok := m.idx.questionByID[q.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if m.data.Questions[i].Version != expected {
+		return ErrConflict
+	}
+	return m.commit(op{Kind: opUpdateQuestion, Question: &q, Audit: m.chain(e)})
+}
+
+func (m *Memory) GetQuestion(id string) (model.Question, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	i, ok := m.idx.questionByID[id]
+	if !ok {
+		return model.Question{}, ErrNotFound
+	}
+	return m.data.Questions[i], nil
+}
+
+func (m *Memory) QuestionsByTender(tenderID string) ([]model.Question, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var res []model.Question
+	for _, i := range m.idx.questionsByTender.union([]string{tenderID}) {
+		res = append(res, m.data.Questions[i])
+	}
+	return res, nil
+}
+
+func (m *Memory) AddReview(r model.BidReview, b model.Bid, expected int, e model.AuditEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.expectBid(b.ID, expected); err != nil {
+		return err
+	}
+	return m.commit(op{Kind: opUpdateBid, Bid: &b, Review: &r, Audit: m.chain(e)})
+}
+
+func (m *Memory) ReviewsByAuthor(authorID string) ([]model.BidReview, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var res []model.BidReview
+	for _, i := range m.idx.reviewsByAuthor.union([]string{authorID}) {
+		res = append(res, m.data.Reviews[i])
+	}
+	return res, nil
+}
+
+// chain returns e chained to the last stored event, to be committed with
+// the change it records. The caller holds the write lock.
+func (m *Memory) chain(e model.AuditEvent) *model.AuditEvent {
+	var last model.AuditEvent
+	if n := len(m.data.Audit); n > 0 {
+		last = m.data.Audit[n-1]
+	}
+	e = e.Chain(last)
+	return &e
+}
+
+// ListAudit scans only the events of f.TenderIDs when it sets them.
+func (m *Memory) ListAudit(f AuditFilter) ([]model.AuditEvent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var res []model.AuditEvent
+	if len(f.TenderIDs) > 0 {
+		for _, i := range m.idx.auditByTender.union(f.TenderIDs) {
+			if e := m.data.Audit[i]; matchAudit(f, e) {
+				res = append(res, e)
+			}
+		}
+		return res, nil
+	}
+	for _, e := range m.data.Audit {
+		if matchAudit(f, e) {
+			res = append(res, e)
+		}
+	}
+	return res, nil
+}
+
+func (m *Memory) GetEmployee(username string) (model.Employee, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	i, ok := m.idx.employeeByUsername[username]
+	if !ok {
+		return model.Employee{}, ErrNotFound
+	}
+	return m.data.Employees[i], nil
+}
+
+func (m *Memory) GetEmployeeByID(id string) (model.Employee, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	i, ok := m.idx.employeeByID[id]
+	if !ok {
+		return model.Employee{}, ErrNotFound
+	}
+	return m.data.Employees[i], nil
+}
+
+func (m *Memory) GetOrganization(id string) (model.Organization, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	i, ok := m.idx.organizationByID[id]
+	if !ok {
+		return model.Organization{}, ErrNotFound
+	}
+	return m.data.Organizations[i], nil
+}
+
+func (m *Memory) IsResponsible(orgID, userID string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.idx.responsible[responsibleKey{orgID, userID}], nil
+}
+
+func (m *Memory) OrganizationResponsibles(orgID string) ([]model.Employee, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var res []model.Employee
+	for _, i := range m.idx.responsiblesByOrg.union([]string{orgID}) {
+		if e, ok := m.idx.employeeByID[m.data.Responsibles[i].UserID]; ok {
+			res = append(res, m.data.Employees[e])
+		}
+	}
+	return res, nil
+}
+
+func (m *Memory) ResponsibleOrganizations(userID string) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var res []string
+	for _, i := range m.idx.responsiblesByUser.union([]string{userID}) {
+		res = append(res, m.data.Responsibles[i].OrganizationID)
+	}
+	return res, nil
+}
+
+// AddEmployee, AddOrganization and AddResponsible seed the directory. The
+// API never mutates it; they exist for tests and fixtures.
+func (m *Memory) AddEmployee(e model.Employee) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.commit(op{Kind: opAddEmployee, Employee: &e})
+}
+
+func (m *Memory) AddOrganization(o model.Organization) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.commit(op{Kind: opAddOrganization, Organization: &o})
+}
+
+func (m *Memory) AddResponsible(r model.OrganizationResponsible) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.commit(op{Kind: opAddResponsible, Responsible: &r})
+}
@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"tender/internal/model"
+)
+
+// lookup is a query through an index and the IDs it should return.
+type lookup struct {
+	name string
+	ids  func() ([]string, error)
+	want []string
+}
+
+// check runs each lookup and fails the test on a differing result.
+func check(t *testing.T, step string, lookups []lookup) {
+	t.Helper()
+	for _, l := range lookups {
+		got, err := l.ids()
+		if err != nil {
+			t.Fatal(err)
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, l.want) {
+			t.Errorf("%s: %s = %v, want %v", step, l.name, got, l.want)
+		}
+	}
+}
+
+func tenders(m *Memory, f TenderFilter) func() ([]string, error) {
+	return creator(func(string) ([]model.Tender, error) { return m.ListTenders(f) }, "")
+}
+
+func creator(list func(string) ([]model.Tender, error), username string) func() ([]string, error) {
+	return func() ([]string, error) {
+		ts, err := list(username)
+		var ids []string
+		for _, t := range ts {
+			ids = append(ids, t.ID)
+		}
+		return ids, err
+	}
+}
+
+func bids(list func(string) ([]model.Bid, error), key string) func() ([]string, error) {
+	return func() ([]string, error) {
+		bs, err := list(key)
+		var ids []string
+		for _, b := range bs {
+			ids = append(ids, b.ID)
+		}
+		return ids, err
+	}
+}
+
+// store stores tn as the next version of its tender in m.
+func store(t *testing.T, m *Memory, tn model.Tender) model.Tender {
+	t.Helper()
+	cur, err := m.GetTender(tn.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tn.Version = cur.Version + 1
+	if err := m.UpdateTender(tn, cur.Version, model.AuditEvent{Action: model.AuditTenderEdit, TenderID: tn.ID}); err != nil {
+		t.Fatal(err)
+	}
+	return tn
+}
+
+// storeBid stores b as the next version of its bid in m.
+func storeBid(t *testing.T, m *Memory, b model.Bid) model.Bid {
+	t.Helper()
+	cur, err := m.GetBid(b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Version = cur.Version + 1
+	if err := m.UpdateBid(b, cur.Version, model.AuditEvent{Action: model.AuditBidEdit, EntityID: b.ID}); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestTenderIndexes(t *testing.T) {
+	m := NewMemory()
+	noon := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, tn := range []model.Tender{
+		{ID: "t1", ServiceType: model.ServiceConstruction, Status: model.TenderCreated, OrganizationID: "o1", CreatorUsername: "alice"},
+		{ID: "t2", ServiceType: model.ServiceDelivery, Status: model.TenderPublished, OrganizationID: "o1", CreatorUsername: "bob", DecisionDeadline: &noon},
+		{ID: "t3", ServiceType: model.ServiceDelivery, Status: model.TenderPublished, OrganizationID: "o2", CreatorUsername: "alice"},
+	} {
+		tn.Version = 1
+		if err := m.AddTender(tn, model.AuditEvent{Action: model.AuditTenderCreate, TenderID: tn.ID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lookups := func(want ...[]string) []lookup {
+		return []lookup{
+			{"construction", tenders(m, TenderFilter{ServiceTypes: []string{model.ServiceConstruction}}), want[0]},
+			{"delivery", tenders(m, TenderFilter{ServiceTypes: []string{model.ServiceDelivery}}), want[1]},
+			{"published", tenders(m, TenderFilter{Statuses: []string{model.TenderPublished}}), want[2]},
+			{"org o1", tenders(m, TenderFilter{OrganizationIDs: []string{"o1"}}), want[3]},
+			{"alice's", tenders(m, TenderFilter{CreatorUsername: "alice"}), want[4]},
+			{"TendersByCreator(bob)", creator(m.TendersByCreator, "bob"), want[5]},
+			{"published delivery of o1", tenders(m, TenderFilter{
+				ServiceTypes: []string{model.ServiceDelivery}, Statuses: []string{model.TenderPublished}, OrganizationIDs: []string{"o1"},
+			}), want[6]},
+			{"expired at noon", tenders(m, TenderFilter{ExpiredBy: noon}), want[7]},
+		}
+	}
+	check(t, "added", lookups(
+		[]string{"t1"}, []string{"t2", "t3"}, []string{"t2", "t3"}, []string{"t1", "t2"},
+		[]string{"t1", "t3"}, []string{"t2"}, []string{"t2"}, []string{"t2"},
+	))
+
+	t1, _ := m.GetTender("t1")
+	old := t1
+	t1.ServiceType = model.ServiceDelivery
+	t1.Status = model.TenderPublished
+	t1.OrganizationID = "o2"
+	t1.CreatorUsername = "bob"
+	t1.DecisionDeadline = &noon
+	store(t, m, t1)
+	check(t, "moved", lookups(
+		nil, []string{"t1", "t2", "t3"}, []string{"t1", "t2", "t3"}, []string{"t2"},
+		[]string{"t3"}, []string{"t1", "t2"}, []string{"t2"}, []string{"t1", "t2"},
+	))
+
+	// A refused update changes neither the tender nor its index entries.
+	stale := old
+	stale.Status = model.TenderClosed
+	if err := m.UpdateTender(stale, old.Version, model.AuditEvent{}); err != ErrConflict {
+		t.Fatalf("stale update: err = %v, want ErrConflict", err)
+	}
+	check(t, "refused", []lookup{{"closed", tenders(m, TenderFilter{Statuses: []string{model.TenderClosed}}), nil}})
+
+	// Rolling back stores the old contents as a new version, which moves
+	// every entry back.
+	store(t, m, old)
+	check(t, "rolled back", lookups(
+		[]string{"t1"}, []string{"t2", "t3"}, []string{"t2", "t3"}, []string{"t1", "t2"},
+		[]string{"t1", "t3"}, []string{"t2"}, []string{"t2"}, []string{"t2"},
+	))
+}
+
+func TestBidIndexes(t *testing.T) {
+	m := NewMemory()
+	for _, b := range []model.Bid{
+		{ID: "b1", TenderID: "t1", AuthorID: "u1"},
+		{ID: "b2", TenderID: "t1", AuthorID: "u2"},
+		{ID: "b3", TenderID: "t2", AuthorID: "u1"},
+	} {
+		b.Version = 1
+		if err := m.AddBid(b, model.AuditEvent{Action: model.AuditBidCreate, EntityID: b.ID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lookups := func(want ...[]string) []lookup {
+		return []lookup{
+			{"BidsByTender(t1)", bids(m.BidsByTender, "t1"), want[0]},
+			{"BidsByTender(t2)", bids(m.BidsByTender, "t2"), want[1]},
+			{"BidsByAuthor(u1)", bids(m.BidsByAuthor, "u1"), want[2]},
+			{"BidsByAuthor(u2)", bids(m.BidsByAuthor, "u2"), want[3]},
+		}
+	}
+	check(t, "added", lookups([]string{"b1", "b2"}, []string{"b3"}, []string{"b1", "b3"}, []string{"b2"}))
+
+	b1, _ := m.GetBid("b1")
+	old := b1
+	b1.TenderID = "t2"
+	b1.AuthorID = "u2"
+	storeBid(t, m, b1)
+	check(t, "moved", lookups([]string{"b2"}, []string{"b1", "b3"}, []string{"b3"}, []string{"b1", "b2"}))
+
+	if err := m.UpdateBid(old, old.Version, model.AuditEvent{}); err != ErrConflict {
+		t.Fatalf("stale update: err = %v, want ErrConflict", err)
+	}
+	check(t, "refused", lookups([]string{"b2"}, []string{"b1", "b3"}, []string{"b3"}, []string{"b1", "b2"}))
+
+	storeBid(t, m, old)
+	check(t, "rolled back", lookups([]string{"b1", "b2"}, []string{"b3"}, []string{"b1", "b3"}, []string{"b2"}))
+}
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"time"
+
+	"tender/internal/model"
+)
+
+// Kinds of op.
+const (
+	opAddTender      = "addTender"
+	opUpdateTender   = "updateTender"
+	opAddBid         = "addBid"
+	opUpdateBid      = "updateBid"
+	opAddDecision    = "addDecision"
+	opPutEvaluation  = "putEvaluation"
+	opAddQuestion    = "addQuestion"
+	opUpdateQuestion = "updateQuestion"
+	// opAddReview only carries a review. Reviews now come with the bid
+	// update that records them; logs written before still replay.
+	opAddReview = "addReview"
+	// opAppendAudit only carries an event. Events now come with the op of
+	// the change they record; logs written before still replay.
+	opAppendAudit     = "appendAudit"
+	opAddEmployee     = "addEmployee"
+	opAddOrganization = "addOrganization"
+	opAddResponsible  = "addResponsible"
+	opPruneVersions   = "pruneVersions"
+)
+
+// op is a single mutation of Data. Memory validates a mutation, hands the op
+// to its persist hook and only then applies it, so the file backend can log
+// it ahead of the change and replay it after a crash.
+type op struct {
+	Seq  uint64 `json:"seq"`
+	Kind string `json:"op"`
+
+	Tender       *model.Tender                  `json:"tender,omitempty"`
+	Bid          *model.Bid                     `json:"bid,omitempty"`
+	Decision     *model.BidDecision             `json:"decision,omitempty"`
+	Evaluation   *model.BidEvaluation           `json:"evaluation,omitempty"`
+	Question     *model.Question                `json:"question,omitempty"`
+	Review       *model.BidReview               `json:"review,omitempty"`
+	Audit        *model.AuditEvent              `json:"audit,omitempty"`
+	Employee     *model.Employee                `json:"employee,omitempty"`
+	Organization *model.Organization            `json:"organization,omitempty"`
+	Responsible  *model.OrganizationResponsible `json:"responsible,omitempty"`
+	Prune        *prune                         `json:"prune,omitempty"`
+}
+
+// prune holds the arguments of PruneVersions.
+type prune struct {
+	KeepLast int       `json:"keepLast"`
+	Cutoff   time.Time `json:"cutoff"`
+}
+
+// apply performs o on the data and keeps the indexes in sync. Updates
+// replace the entity with the same ID, which the caller has already checked
+// exists, and archive the replaced state. The review and the audit event
+// of o, if any, are added with the change.
+func (m *Memory) apply(o op) {
+	d, x := &m.data, &m.idx
+	switch o.Kind {
+	case opAddTender:
+		t := *o.Tender
+		t.History = nil
+		d.Tenders = append(d.Tenders, t)
+		x.addTender(t, len(d.Tenders)-1)
+	case opUpdateTender:
+		if i, ok := x.tenderByID[o.Tender.ID]; ok {
+			t, old := *o.Tender, d.Tenders[i]
+			t.History = nil
+			d.TenderVersions[t.ID] = append(d.TenderVersions[t.ID], old.Snapshot())
+			x.updateTender(old, t, i)
+			d.Tenders[i] = t
+		}
+	case opAddBid:
+		b := *o.Bid
+		b.History = nil
+		d.Bids = append(d.Bids, b)
+		x.addBid(b, len(d.Bids)-1)
+	case opUpdateBid:
+		if i, ok := x.bidByID[o.Bid.ID]; ok {
+			b, old := *o.Bid, d.Bids[i]
+			b.History = nil
+			d.BidVersions[b.ID] = append(d.BidVersions[b.ID], old.Snapshot())
+			x.updateBid(old, b, i)
+			d.Bids[i] = b
+		}
+	case opAddDecision:
+		d.Decisions = append(d.Decisions, *o.Decision)
+		x.addDecision(*o.Decision, len(d.Decisions)-1)
+	case opPutEvaluation:
+		e := *o.Evaluation
+		if i, ok := x.evaluationByVoter[voteKey{e.BidID, e.EvaluatorID}]; ok {
+			e.ID, e.CreatedAt = d.Evaluations[i].ID, d.Evaluations[i].CreatedAt
+			d.Evaluations[i] = e
+			break
+		}
+		d.Evaluations = append(d.Evaluations, e)
+		x.addEvaluation(e, len(d.Evaluations)-1)
+	case opAddQuestion:
+		d.Questions = append(d.Questions, *o.Question)
+		x.addQuestion(*o.Question, len(d.Questions)-1)
+	case opUpdateQuestion:
+		if i, ok := x.questionByID[o.Question.ID]; ok {
+			d.Questions[i] = *o.Question
+		}
+	case opAddEmployee:
+		d.Employees = append(d.Employees, *o.Employee)
+		x.addEmployee(*o.Employee, len(d.Employees)-1)
+	case opAddOrganization:
+		d.Organizations = append(d.Organizations, *o.Organization)
+		x.organizationByID[o.Organization.ID] = len(d.Organizations) - 1
+	case opAddResponsible:
+		d.Responsibles = append(d.Responsibles, *o.Responsible)
+		x.addResponsible(*o.Responsible, len(d.Responsibles)-1)
+	case opPruneVersions:
+		d.pruneVersions(o.Prune.KeepLast, o.Prune.Cutoff)
+	}
+	if o.Review != nil {
+		d.Reviews = append(d.Reviews, *o.Review)
+		x.addReview(*o.Review, len(d.Reviews)-1)
+	}
+	if o.Audit != nil {
+		d.Audit = append(d.Audit, *o.Audit)
+		x.auditByTender.add(o.Audit.TenderID, len(d.Audit)-1)
+	}
+}
@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"tender/internal/model"
+	"tender/internal/storage"
+)
+
+// auditLock is the pg_advisory_xact_lock key that serializes appends to the
+// audit log, so that every event is chained to the one before it.
+const auditLock = 7359002
+
+const auditColumns = `seq, at, actor, action, entity_type, entity_id, tender_id, from_version, to_version,
+	request_id, prev_hash, hash`
+
+// appendAudit chains e to the last stored event and inserts it in tx, the
+// transaction of the change it records. The lock is taken last and held
+// until tx ends, so that events are chained in the order they commit.
+func appendAudit(tx *sql.Tx, e model.AuditEvent) error {
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1)`, auditLock); err != nil {
+		return err
+	}
+	var last model.AuditEvent
+	err := tx.QueryRow(`SELECT seq, hash FROM audit_event ORDER BY seq DESC LIMIT 1`).Scan(&last.Seq, &last.Hash)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	e = e.Chain(last)
+	_, err = tx.Exec(`INSERT INTO audit_event (`+auditColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+		e.Seq, e.At, e.Actor, e.Action, e.EntityType, e.EntityID, e.TenderID, e.FromVersion, e.ToVersion,
+		e.RequestID, e.PrevHash, e.Hash)
+	return err
+}
+
+func (s *Storage) ListAudit(f storage.AuditFilter) ([]model.AuditEvent, error) {
+	var (
+		conds []string
+		args  []any
+	)
+	where := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if f.Actor != "" {
+		where(`actor = $%d`, f.Actor)
+	}
+	if f.Action != "" {
+		where(`action = $%d`, f.Action)
+	}
+	if f.EntityType != "" {
+		where(`entity_type = $%d`, f.EntityType)
+	}
+	if f.EntityID != "" {
+		where(`entity_id = $%d`, f.EntityID)
+	}
+	if len(f.TenderIDs) > 0 {
+		where(`tender_id = ANY($%d)`, pq.Array(f.TenderIDs))
+	}
+	if !f.Since.IsZero() {
+		where(`at >= $%d`, f.Since)
+	}
+	if !f.Until.IsZero() {
+		where(`at < $%d`, f.Until)
+	}
+	query := `SELECT ` + auditColumns + ` FROM audit_event `
+	if len(conds) > 0 {
+		query += `WHERE ` + strings.Join(conds, ` AND `) + ` `
+	}
+	rows, err := s.db.Query(query+`ORDER BY seq`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []model.AuditEvent
+	for rows.Next() {
+		var e model.AuditEvent
+		if err := rows.Scan(&e.Seq, &e.At, &e.Actor, &e.Action, &e.EntityType, &e.EntityID, &e.TenderID,
+			&e.FromVersion, &e.ToVersion, &e.RequestID, &e.PrevHash, &e.Hash); err != nil {
+			return nil, err
+		}
+		res = append(res, e)
+	}
+	return res, rows.Err()
+}
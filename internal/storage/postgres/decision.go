@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"database/sql"
+
+	"tender/internal/model"
+)
+
+func (s *Storage) AddDecision(d model.BidDecision, e model.AuditEvent) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`INSERT INTO bid_decision (id, bid_id, user_id, username, decision, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6)`,
+			d.ID, d.BidID, d.UserID, d.Username, d.Decision, d.CreatedAt)
+		if err != nil {
+			return translate(err)
+		}
+		return appendAudit(tx, e)
+	})
+}
+
+func (s *Storage) ListDecisions(bidID string) ([]model.BidDecision, error) {
+	rows, err := s.db.Query(`SELECT id, bid_id, user_id, username, decision, created_at
+		FROM bid_decision WHERE bid_id = $1 ORDER BY created_at`, bidID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []model.BidDecision
+	for rows.Next() {
+		var d model.BidDecision
+		if err := rows.Scan(&d.ID, &d.BidID, &d.UserID, &d.Username, &d.Decision, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, d)
+	}
+	return res, rows.Err()
+}
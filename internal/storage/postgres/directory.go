@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+
+	"tender/internal/model"
+	"tender/internal/storage"
+)
+
+// Identifiers coming from requests are arbitrary strings, so UUID columns are
+// compared as text to avoid cast errors on malformed input.
+
+const employeeColumns = `e.id::text, e.username, COALESCE(e.first_name, ''), COALESCE(e.last_name, ''),
+	COALESCE(e.created_at, now()), COALESCE(e.updated_at, now())`
+
+func scanEmployee(row interface{ Scan(...any) error }) (model.Employee, error) {
+	var e model.Employee
+	err := row.Scan(&e.ID, &e.Username, &e.FirstName, &e.LastName, &e.CreatedAt, &e.UpdatedAt)
+	return e, err
+}
+
+func (s *Storage) GetEmployee(username string) (model.Employee, error) {
+	e, err := scanEmployee(s.db.QueryRow(`SELECT `+employeeColumns+` FROM employee e WHERE e.username = $1`, username))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Employee{}, storage.ErrNotFound
+	}
+	return e, err
+}
+
+func (s *Storage) GetEmployeeByID(id string) (model.Employee, error) {
+	e, err := scanEmployee(s.db.QueryRow(`SELECT `+employeeColumns+` FROM employee e WHERE e.id::text = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Employee{}, storage.ErrNotFound
+	}
+	return e, err
+}
+
+func (s *Storage) GetOrganization(id string) (model.Organization, error) {
+	var o model.Organization
+	err := s.db.QueryRow(`SELECT id::text, name, COALESCE(description, ''), COALESCE(type::text, ''),
+		COALESCE(created_at, now()), COALESCE(updated_at, now())
+		FROM organization WHERE id::text = $1`, id).
+		Scan(&o.ID, &o.Name, &o.Description, &o.Type, &o.CreatedAt, &o.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Organization{}, storage.ErrNotFound
+	}
+	return o, err
+}
+
+func (s *Storage) IsResponsible(orgID, userID string) (bool, error) {
+	var ok bool
+	err := s.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM organization_responsible
+		WHERE organization_id::text = $1 AND user_id::text = $2)`, orgID, userID).Scan(&ok)
+	return ok, err
+}
+
+func (s *Storage) OrganizationResponsibles(orgID string) ([]model.Employee, error) {
+	rows, err := s.db.Query(`SELECT `+employeeColumns+`
+		FROM organization_responsible r JOIN employee e ON e.id = r.user_id
+		WHERE r.organization_id::text = $1
+		ORDER BY e.username`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []model.Employee
+	for rows.Next() {
+		e, err := scanEmployee(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, e)
+	}
+	return res, rows.Err()
+}
+
+func (s *Storage) ResponsibleOrganizations(userID string) ([]string, error) {
+	rows, err := s.db.Query(`SELECT organization_id::text FROM organization_responsible
+		WHERE user_id::text = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		res = append(res, id)
+	}
+	return res, rows.Err()
+}
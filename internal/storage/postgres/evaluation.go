@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"tender/internal/model"
+)
+
+func (s *Storage) PutEvaluation(e model.BidEvaluation, event model.AuditEvent) error {
+	scores, err := json.Marshal(e.Scores)
+	if err != nil {
+		return err
+	}
+	return s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`INSERT INTO bid_evaluation
+			(id, bid_id, tender_id, evaluator_id, evaluator_username, scores, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (bid_id, evaluator_id) DO UPDATE
+			SET evaluator_username = EXCLUDED.evaluator_username, scores = EXCLUDED.scores,
+			updated_at = EXCLUDED.updated_at`,
+			e.ID, e.BidID, e.TenderID, e.EvaluatorID, e.EvaluatorUsername, scores, e.CreatedAt, e.UpdatedAt)
+		if err != nil {
+			return translate(err)
+		}
+		return appendAudit(tx, event)
+	})
+}
+
+func (s *Storage) EvaluationsByTender(tenderID string) ([]model.BidEvaluation, error) {
+	rows, err := s.db.Query(`SELECT id, bid_id, tender_id, evaluator_id, evaluator_username, scores, created_at,
+		updated_at
+		FROM bid_evaluation WHERE tender_id = $1 ORDER BY created_at`, tenderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []model.BidEvaluation
+	for rows.Next() {
+		var e model.BidEvaluation
+		if err := rows.Scan(&e.ID, &e.BidID, &e.TenderID, &e.EvaluatorID, &e.EvaluatorUsername,
+			jsonColumn{&e.Scores}, &e.CreatedAt, &e.UpdatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, e)
+	}
+	return res, rows.Err()
+}
@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+)
+
+// PruneVersions deletes archived versions by rank within their entity and
+// by age, in one transaction for both tables.
+func (s *Storage) PruneVersions(keepLast int, cutoff time.Time) (int, error) {
+	var n int64
+	err := s.withTx(func(tx *sql.Tx) error {
+		for _, table := range []struct{ name, key string }{
+			{"tender_version", "tender_id"},
+			{"bid_version", "bid_id"},
+		} {
+			if keepLast > 0 {
+				res, err := tx.Exec(`DELETE FROM `+table.name+` WHERE (`+table.key+`, version) IN (
+					SELECT `+table.key+`, version FROM (
+						SELECT `+table.key+`, version,
+							row_number() OVER (PARTITION BY `+table.key+` ORDER BY version DESC) AS rank
+						FROM `+table.name+`) ranked
+					WHERE rank > $1)`, keepLast)
+				if err != nil {
+					return err
+				}
+				deleted, _ := res.RowsAffected()
+				n += deleted
+			}
+			if !cutoff.IsZero() {
+				res, err := tx.Exec(`DELETE FROM `+table.name+` WHERE updated_at < $1`, cutoff)
+				if err != nil {
+					return err
+				}
+				deleted, _ := res.RowsAffected()
+				n += deleted
+			}
+		}
+		return nil
+	})
+	return int(n), err
+}
@@ -0,0 +1,63 @@
+CREATE TABLE IF NOT EXISTS tender (
+    id               VARCHAR(100) PRIMARY KEY,
+    name             VARCHAR(100) NOT NULL,
+    description      VARCHAR(500) NOT NULL,
+    service_type     VARCHAR(50)  NOT NULL,
+    organization_id  VARCHAR(100) NOT NULL,
+    creator_username VARCHAR(50)  NOT NULL,
+    status           VARCHAR(20)  NOT NULL,
+    version          INT          NOT NULL,
+    created_at       TIMESTAMPTZ  NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS tender_creator_idx ON tender (creator_username);
+
+CREATE TABLE IF NOT EXISTS tender_version (
+    tender_id    VARCHAR(100) NOT NULL REFERENCES tender (id) ON DELETE CASCADE,
+    version      INT          NOT NULL,
+    name         VARCHAR(100) NOT NULL,
+    description  VARCHAR(500) NOT NULL,
+    service_type VARCHAR(50)  NOT NULL,
+    status       VARCHAR(20)  NOT NULL,
+    created_at   TIMESTAMPTZ  NOT NULL,
+    PRIMARY KEY (tender_id, version)
+);
+
+CREATE TABLE IF NOT EXISTS bid (
+    id          VARCHAR(100) PRIMARY KEY,
+    name        VARCHAR(100)  NOT NULL,
+    description VARCHAR(500)  NOT NULL,
+    tender_id   VARCHAR(100)  NOT NULL,
+    author_type VARCHAR(20)   NOT NULL,
+    author_id   VARCHAR(100)  NOT NULL,
+    status      VARCHAR(20)   NOT NULL,
+    decision    VARCHAR(20)   NOT NULL DEFAULT '',
+    feedback    VARCHAR(1000) NOT NULL DEFAULT '',
+    version     INT           NOT NULL,
+    created_at  TIMESTAMPTZ   NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS bid_tender_idx ON bid (tender_id);
+CREATE INDEX IF NOT EXISTS bid_author_idx ON bid (author_id);
+
+CREATE TABLE IF NOT EXISTS bid_version (
+    bid_id      VARCHAR(100)  NOT NULL REFERENCES bid (id) ON DELETE CASCADE,
+    version     INT           NOT NULL,
+    name        VARCHAR(100)  NOT NULL,
+    description VARCHAR(500)  NOT NULL,
+    status      VARCHAR(20)   NOT NULL,
+    decision    VARCHAR(20)   NOT NULL DEFAULT '',
+    feedback    VARCHAR(1000) NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ   NOT NULL,
+    PRIMARY KEY (bid_id, version)
+);
+
+CREATE TABLE IF NOT EXISTS bid_review (
+    id              VARCHAR(100) PRIMARY KEY,
+    tender_id       VARCHAR(100)  NOT NULL,
+    author_username VARCHAR(50)   NOT NULL,
+    description     VARCHAR(1000) NOT NULL,
+    created_at      TIMESTAMPTZ   NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS bid_review_tender_author_idx ON bid_review (tender_id, author_username);
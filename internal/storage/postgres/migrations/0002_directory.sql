@@ -0,0 +1,33 @@
+-- The employee and organization tables are owned by the surrounding
+-- platform and usually exist already; create them for standalone setups.
+CREATE TABLE IF NOT EXISTS employee (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    username   VARCHAR(50) UNIQUE NOT NULL,
+    first_name VARCHAR(50),
+    last_name  VARCHAR(50),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'organization_type') THEN
+        CREATE TYPE organization_type AS ENUM ('IE', 'LLC', 'JSC');
+    END IF;
+END
+$$;
+
+CREATE TABLE IF NOT EXISTS organization (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name        VARCHAR(100) NOT NULL,
+    description TEXT,
+    type        organization_type,
+    created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS organization_responsible (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID REFERENCES organization (id) ON DELETE CASCADE,
+    user_id         UUID REFERENCES employee (id) ON DELETE CASCADE
+);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS bid_decision (
+    id         VARCHAR(100) PRIMARY KEY,
+    bid_id     VARCHAR(100) NOT NULL REFERENCES bid (id) ON DELETE CASCADE,
+    user_id    VARCHAR(100) NOT NULL,
+    username   VARCHAR(50)  NOT NULL,
+    decision   VARCHAR(20)  NOT NULL,
+    created_at TIMESTAMPTZ  NOT NULL,
+    UNIQUE (bid_id, user_id)
+);
@@ -0,0 +1,11 @@
+-- Reviews are now tied to the bid and its author rather than looked up by
+-- tender and username.
+DROP INDEX IF EXISTS bid_review_tender_author_idx;
+
+ALTER TABLE bid_review
+    ADD COLUMN bid_id            VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN author_id         VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN reviewer_username VARCHAR(50)  NOT NULL DEFAULT '',
+    DROP COLUMN author_username;
+
+CREATE INDEX IF NOT EXISTS bid_review_author_idx ON bid_review (author_id, created_at);
@@ -0,0 +1,4 @@
+-- Indexes behind the tender filters of ListTenders.
+CREATE INDEX IF NOT EXISTS tender_organization_idx ON tender (organization_id);
+CREATE INDEX IF NOT EXISTS tender_service_type_idx ON tender (service_type);
+CREATE INDEX IF NOT EXISTS tender_status_idx ON tender (status);
@@ -0,0 +1,25 @@
+-- Every version records who produced it and when. Existing rows are
+-- attributed to nobody at their creation time.
+ALTER TABLE tender
+    ADD COLUMN updated_by VARCHAR(50) NOT NULL DEFAULT '',
+    ADD COLUMN updated_at TIMESTAMPTZ;
+UPDATE tender SET updated_at = created_at;
+ALTER TABLE tender ALTER COLUMN updated_at SET NOT NULL;
+
+ALTER TABLE tender_version
+    ADD COLUMN updated_by VARCHAR(50) NOT NULL DEFAULT '',
+    ADD COLUMN updated_at TIMESTAMPTZ;
+UPDATE tender_version SET updated_at = created_at;
+ALTER TABLE tender_version ALTER COLUMN updated_at SET NOT NULL;
+
+ALTER TABLE bid
+    ADD COLUMN updated_by VARCHAR(50) NOT NULL DEFAULT '',
+    ADD COLUMN updated_at TIMESTAMPTZ;
+UPDATE bid SET updated_at = created_at;
+ALTER TABLE bid ALTER COLUMN updated_at SET NOT NULL;
+
+ALTER TABLE bid_version
+    ADD COLUMN updated_by VARCHAR(50) NOT NULL DEFAULT '',
+    ADD COLUMN updated_at TIMESTAMPTZ;
+UPDATE bid_version SET updated_at = created_at;
+ALTER TABLE bid_version ALTER COLUMN updated_at SET NOT NULL;
@@ -0,0 +1,14 @@
+-- Optional submission and decision deadlines. Tenders are closed at their
+-- decision deadline when they have one and at their submission deadline
+-- otherwise, which the scheduler that closes them looks up.
+ALTER TABLE tender
+    ADD COLUMN submission_deadline TIMESTAMPTZ,
+    ADD COLUMN decision_deadline TIMESTAMPTZ;
+
+ALTER TABLE tender_version
+    ADD COLUMN submission_deadline TIMESTAMPTZ,
+    ADD COLUMN decision_deadline TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS tender_closing_deadline_idx
+    ON tender ((COALESCE(decision_deadline, submission_deadline)))
+    WHERE COALESCE(decision_deadline, submission_deadline) IS NOT NULL;
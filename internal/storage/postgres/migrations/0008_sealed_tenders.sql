@@ -0,0 +1,11 @@
+-- Sealed tenders hide bid contents until they are opened or their
+-- submission deadline passes. Opening is recorded with who did it and when.
+ALTER TABLE tender
+    ADD COLUMN sealed BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN opened_by VARCHAR(50) NOT NULL DEFAULT '',
+    ADD COLUMN opened_at TIMESTAMPTZ;
+
+ALTER TABLE tender_version
+    ADD COLUMN sealed BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN opened_by VARCHAR(50) NOT NULL DEFAULT '',
+    ADD COLUMN opened_at TIMESTAMPTZ;
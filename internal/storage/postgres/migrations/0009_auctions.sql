@@ -0,0 +1,7 @@
+-- Reverse auctions: the auction settings and standing of a tender, and the
+-- price of each bid.
+ALTER TABLE tender ADD COLUMN auction JSONB;
+ALTER TABLE tender_version ADD COLUMN auction JSONB;
+
+ALTER TABLE bid ADD COLUMN price NUMERIC(19, 4);
+ALTER TABLE bid_version ADD COLUMN price NUMERIC(19, 4);
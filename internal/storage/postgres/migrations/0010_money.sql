@@ -0,0 +1,11 @@
+-- Amounts of money carry an ISO 4217 currency. Prices stored before were
+-- in roubles. Tenders gain an optional budget.
+ALTER TABLE bid ADD COLUMN price_currency CHAR(3);
+ALTER TABLE bid_version ADD COLUMN price_currency CHAR(3);
+UPDATE bid SET price_currency = 'RUB' WHERE price IS NOT NULL;
+UPDATE bid_version SET price_currency = 'RUB' WHERE price IS NOT NULL;
+
+ALTER TABLE tender ADD COLUMN budget NUMERIC(19, 4);
+ALTER TABLE tender ADD COLUMN budget_currency CHAR(3);
+ALTER TABLE tender_version ADD COLUMN budget NUMERIC(19, 4);
+ALTER TABLE tender_version ADD COLUMN budget_currency CHAR(3);
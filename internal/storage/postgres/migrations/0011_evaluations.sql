@@ -0,0 +1,18 @@
+-- Evaluation criteria of tenders and the scores evaluators give bids, one
+-- evaluation per bid and evaluator.
+ALTER TABLE tender ADD COLUMN criteria JSONB;
+ALTER TABLE tender_version ADD COLUMN criteria JSONB;
+
+CREATE TABLE IF NOT EXISTS bid_evaluation (
+    id                 VARCHAR(100) PRIMARY KEY,
+    bid_id             VARCHAR(100) NOT NULL REFERENCES bid (id) ON DELETE CASCADE,
+    tender_id          VARCHAR(100) NOT NULL,
+    evaluator_id       VARCHAR(100) NOT NULL,
+    evaluator_username VARCHAR(50)  NOT NULL,
+    scores             JSONB        NOT NULL,
+    created_at         TIMESTAMPTZ  NOT NULL,
+    updated_at         TIMESTAMPTZ  NOT NULL,
+    UNIQUE (bid_id, evaluator_id)
+);
+
+CREATE INDEX IF NOT EXISTS bid_evaluation_tender_idx ON bid_evaluation (tender_id);
@@ -0,0 +1,5 @@
+-- Tender lots, stored with their tender, and the lots each bid is for.
+ALTER TABLE tender ADD COLUMN lots JSONB;
+ALTER TABLE tender_version ADD COLUMN lots JSONB;
+
+ALTER TABLE bid ADD COLUMN lot_ids TEXT[];
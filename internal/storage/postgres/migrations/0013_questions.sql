@@ -0,0 +1,15 @@
+-- Clarification questions asked on tenders and their answers.
+CREATE TABLE IF NOT EXISTS tender_question (
+    id                VARCHAR(100) PRIMARY KEY,
+    tender_id         VARCHAR(100) NOT NULL REFERENCES tender (id) ON DELETE CASCADE,
+    text              TEXT         NOT NULL,
+    asked_by          VARCHAR(50)  NOT NULL,
+    asked_at          TIMESTAMPTZ  NOT NULL,
+    answer            TEXT         NOT NULL DEFAULT '',
+    answered_by       VARCHAR(50)  NOT NULL DEFAULT '',
+    answered_at       TIMESTAMPTZ,
+    published         BOOLEAN      NOT NULL DEFAULT FALSE,
+    deadline_extended TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS tender_question_tender_idx ON tender_question (tender_id, asked_at);
@@ -0,0 +1,6 @@
+-- Metadata of the documents attached to tenders and bids, versioned with
+-- them. The contents live in the blob store.
+ALTER TABLE tender ADD COLUMN attachments JSONB;
+ALTER TABLE tender_version ADD COLUMN attachments JSONB;
+ALTER TABLE bid ADD COLUMN attachments JSONB;
+ALTER TABLE bid_version ADD COLUMN attachments JSONB;
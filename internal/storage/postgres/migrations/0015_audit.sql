@@ -0,0 +1,29 @@
+-- The hash-chained audit log. Rows are only ever appended: the trigger
+-- rejects changing or deleting them.
+CREATE TABLE IF NOT EXISTS audit_event (
+    seq          BIGINT       PRIMARY KEY,
+    at           TIMESTAMPTZ  NOT NULL,
+    actor        VARCHAR(50)  NOT NULL,
+    action       VARCHAR(50)  NOT NULL,
+    entity_type  VARCHAR(50)  NOT NULL,
+    entity_id    VARCHAR(100) NOT NULL,
+    tender_id    VARCHAR(100) NOT NULL,
+    from_version INT          NOT NULL,
+    to_version   INT          NOT NULL,
+    request_id   VARCHAR(200) NOT NULL,
+    prev_hash    VARCHAR(64)  NOT NULL,
+    hash         VARCHAR(64)  NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_event_tender_idx ON audit_event (tender_id, seq);
+CREATE INDEX IF NOT EXISTS audit_event_entity_idx ON audit_event (entity_id, seq);
+
+CREATE OR REPLACE FUNCTION audit_event_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_event is append-only';
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_event_append_only ON audit_event;
+CREATE TRIGGER audit_event_append_only BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_event
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_event_append_only();
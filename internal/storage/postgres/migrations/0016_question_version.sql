@@ -0,0 +1,4 @@
+-- Answers replace questions with a compare-and-swap on their version.
+-- Questions asked before count from version 0.
+ALTER TABLE tender_question
+    ADD COLUMN version INT NOT NULL DEFAULT 0;
@@ -0,0 +1,12 @@
+-- Tenders without a decision deadline no longer close at their submission
+-- deadline but a configured decision window after it, so the scheduler
+-- looks the two deadlines up apart.
+DROP INDEX IF EXISTS tender_closing_deadline_idx;
+
+CREATE INDEX IF NOT EXISTS tender_decision_deadline_idx
+    ON tender (decision_deadline)
+    WHERE decision_deadline IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS tender_submission_deadline_idx
+    ON tender (submission_deadline)
+    WHERE decision_deadline IS NULL AND submission_deadline IS NOT NULL;
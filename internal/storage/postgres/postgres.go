@@ -0,0 +1,432 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"tender/internal/model"
+	"tender/internal/storage"
+)
+
+var _ storage.Repository = (*Storage)(nil)
+
+// Storage keeps tenders, bids and reviews in PostgreSQL. Archived versions
+// live in the tender_version and bid_version tables, the audit log in
+// audit_event.
+type Storage struct {
+	db *sql.DB
+}
+
+// New connects to the database described by dsn and applies pending
+// migrations.
+func New(dsn string) (*Storage, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Storage{db: db}, nil
+}
+
+func (s *Storage) Close() error {
+	return s.db.Close()
+}
+
+// withTx runs fn in a transaction, committing on success.
+func (s *Storage) withTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+const tenderColumns = `id, name, description, service_type, organization_id, creator_username, status, version, created_at,
+	updated_by, updated_at, submission_deadline, decision_deadline, sealed, opened_by, opened_at, auction, budget,
+	budget_currency, criteria, lots, attachments`
+
+func (s *Storage) AddTender(t model.Tender, e model.AuditEvent) error {
+	auction, err := jsonb(t.Auction)
+	if err != nil {
+		return err
+	}
+	criteria, err := json.Marshal(t.Criteria)
+	if err != nil {
+		return err
+	}
+	lots, err := json.Marshal(t.Lots)
+	if err != nil {
+		return err
+	}
+	attachments, err := json.Marshal(t.Attachments)
+	if err != nil {
+		return err
+	}
+	budget, currency := money(t.Budget)
+	return s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`INSERT INTO tender (`+tenderColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20,
+			$21, $22)`,
+			t.ID, t.Name, t.Description, t.ServiceType, t.OrganizationID,
+			t.CreatorUsername, t.Status, t.Version, t.CreatedAt, t.UpdatedBy, t.UpdatedAt,
+			t.SubmissionDeadline, t.DecisionDeadline, t.Sealed, t.OpenedBy, t.OpenedAt, auction, budget, currency,
+			criteria, lots, attachments)
+		if err != nil {
+			return translate(err)
+		}
+		return appendAudit(tx, e)
+	})
+}
+
+func (s *Storage) UpdateTender(t model.Tender, expected int, e model.AuditEvent) error {
+	auction, err := jsonb(t.Auction)
+	if err != nil {
+		return err
+	}
+	criteria, err := json.Marshal(t.Criteria)
+	if err != nil {
+		return err
+	}
+	lots, err := json.Marshal(t.Lots)
+	if err != nil {
+		return err
+	}
+	attachments, err := json.Marshal(t.Attachments)
+	if err != nil {
+		return err
+	}
+	budget, currency := money(t.Budget)
+	return s.withTx(func(tx *sql.Tx) error {
+		// Archive the state being replaced; if the update below does not
+		// match, the transaction is rolled back with it.
+		if _, err := tx.Exec(`INSERT INTO tender_version
+			(tender_id, version, name, description, service_type, status, created_at, updated_by, updated_at,
+			submission_deadline, decision_deadline, sealed, opened_by, opened_at, auction, budget, budget_currency,
+			criteria, lots, attachments)
+			SELECT id, version, name, description, service_type, status, created_at, updated_by, updated_at,
+			submission_deadline, decision_deadline, sealed, opened_by, opened_at, auction, budget, budget_currency,
+			criteria, lots, attachments
+			FROM tender WHERE id = $1 AND version = $2
+			ON CONFLICT (tender_id, version) DO NOTHING`, t.ID, expected); err != nil {
+			return err
+		}
+		res, err := tx.Exec(`UPDATE tender SET name = $2, description = $3, service_type = $4,
+			organization_id = $5, creator_username = $6, status = $7, version = $8, created_at = $9,
+			updated_by = $10, updated_at = $11, submission_deadline = $12, decision_deadline = $13,
+			sealed = $14, opened_by = $15, opened_at = $16, auction = $17, budget = $18, budget_currency = $19,
+			criteria = $20, lots = $21, attachments = $22
+			WHERE id = $1 AND version = $23`,
+			t.ID, t.Name, t.Description, t.ServiceType, t.OrganizationID,
+			t.CreatorUsername, t.Status, t.Version, t.CreatedAt, t.UpdatedBy, t.UpdatedAt,
+			t.SubmissionDeadline, t.DecisionDeadline, t.Sealed, t.OpenedBy, t.OpenedAt, auction, budget, currency,
+			criteria, lots, attachments, expected)
+		if err != nil {
+			return err
+		}
+		if err := expectOne(tx, res, "tender", t.ID); err != nil {
+			return err
+		}
+		return appendAudit(tx, e)
+	})
+}
+
+func (s *Storage) GetTender(id string) (model.Tender, error) {
+	res, err := s.queryTenders(`WHERE id = $1`, id)
+	if err != nil {
+		return model.Tender{}, err
+	}
+	if len(res) == 0 {
+		return model.Tender{}, storage.ErrNotFound
+	}
+	return res[0], nil
+}
+
+func (s *Storage) ListTenders(f storage.TenderFilter) ([]model.Tender, error) {
+	var (
+		conds []string
+		args  []any
+	)
+	where := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if len(f.ServiceTypes) > 0 {
+		where(`service_type = ANY($%d)`, pq.Array(f.ServiceTypes))
+	}
+	if len(f.Statuses) > 0 {
+		where(`status = ANY($%d)`, pq.Array(f.Statuses))
+	}
+	if len(f.OrganizationIDs) > 0 {
+		where(`organization_id = ANY($%d)`, pq.Array(f.OrganizationIDs))
+	}
+	if f.CreatorUsername != "" {
+		where(`creator_username = $%d`, f.CreatorUsername)
+	}
+	if !f.ExpiredBy.IsZero() {
+		where(`decision_deadline <= $%d`, f.ExpiredBy)
+		if f.DecisionWindow > 0 {
+			args = append(args, f.ExpiredBy.Add(-f.DecisionWindow))
+			conds[len(conds)-1] = fmt.Sprintf(`(%s OR decision_deadline IS NULL AND submission_deadline <= $%d)`,
+				conds[len(conds)-1], len(args))
+		}
+	}
+	if len(conds) == 0 {
+		return s.queryTenders(``)
+	}
+	return s.queryTenders(`WHERE `+strings.Join(conds, ` AND `), args...)
+}
+
+func (s *Storage) TendersByCreator(username string) ([]model.Tender, error) {
+	return s.queryTenders(`WHERE creator_username = $1`, username)
+}
+
+func (s *Storage) queryTenders(where string, args ...any) ([]model.Tender, error) {
+	rows, err := s.db.Query(`SELECT `+tenderColumns+` FROM tender `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	return s.scanTenders(rows)
+}
+
+func (s *Storage) scanTenders(rows *sql.Rows) ([]model.Tender, error) {
+	defer rows.Close()
+	var res []model.Tender
+	for rows.Next() {
+		var (
+			t      model.Tender
+			budget nullMoney
+			err    error
+		)
+		if err := rows.Scan(&t.ID, &t.Name, &t.Description, &t.ServiceType, &t.OrganizationID,
+			&t.CreatorUsername, &t.Status, &t.Version, &t.CreatedAt, &t.UpdatedBy, &t.UpdatedAt,
+			&t.SubmissionDeadline, &t.DecisionDeadline, &t.Sealed, &t.OpenedBy, &t.OpenedAt,
+			nullJSON[model.Auction]{&t.Auction}, &budget.amount, &budget.currency,
+			jsonColumn{&t.Criteria}, jsonColumn{&t.Lots}, jsonColumn{&t.Attachments}); err != nil {
+			return nil, err
+		}
+		if t.Budget, err = budget.money(); err != nil {
+			return nil, err
+		}
+		res = append(res, t)
+	}
+	return res, rows.Err()
+}
+
+func (s *Storage) TenderVersions(id string) ([]model.TenderVersion, error) {
+	rows, err := s.db.Query(`SELECT version, name, description, service_type, status, created_at,
+		updated_by, updated_at, submission_deadline, decision_deadline, sealed, opened_by, opened_at, auction,
+		budget, budget_currency, criteria, lots, attachments
+		FROM tender_version WHERE tender_id = $1 ORDER BY version`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []model.TenderVersion
+	for rows.Next() {
+		var (
+			v      model.TenderVersion
+			budget nullMoney
+			err    error
+		)
+		if err := rows.Scan(&v.Version, &v.Name, &v.Description, &v.ServiceType, &v.Status, &v.CreatedAt,
+			&v.UpdatedBy, &v.UpdatedAt, &v.SubmissionDeadline, &v.DecisionDeadline,
+			&v.Sealed, &v.OpenedBy, &v.OpenedAt, nullJSON[model.Auction]{&v.Auction},
+			&budget.amount, &budget.currency, jsonColumn{&v.Criteria}, jsonColumn{&v.Lots},
+			jsonColumn{&v.Attachments}); err != nil {
+			return nil, err
+		}
+		if v.Budget, err = budget.money(); err != nil {
+			return nil, err
+		}
+		res = append(res, v)
+	}
+	return res, rows.Err()
+}
+
+const bidColumns = `id, name, description, tender_id, author_type, author_id, status, decision, feedback, version, created_at,
+	updated_by, updated_at, price, price_currency, lot_ids, attachments`
+
+func (s *Storage) AddBid(b model.Bid, e model.AuditEvent) error {
+	attachments, err := json.Marshal(b.Attachments)
+	if err != nil {
+		return err
+	}
+	price, currency := money(b.Price)
+	return s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`INSERT INTO bid (`+bidColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)`,
+			b.ID, b.Name, b.Description, b.TenderID, b.AuthorType, b.AuthorID,
+			b.Status, b.Decision, b.Feedback, b.Version, b.CreatedAt, b.UpdatedBy, b.UpdatedAt, price, currency,
+			pq.Array(b.LotIDs), attachments)
+		if err != nil {
+			return translate(err)
+		}
+		return appendAudit(tx, e)
+	})
+}
+
+func (s *Storage) UpdateBid(b model.Bid, expected int, e model.AuditEvent) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		if err := updateBid(tx, b, expected); err != nil {
+			return err
+		}
+		return appendAudit(tx, e)
+	})
+}
+
+// updateBid replaces the bid at version expected with b in tx, archiving
+// the replaced state.
+func updateBid(tx *sql.Tx, b model.Bid, expected int) error {
+	attachments, err := json.Marshal(b.Attachments)
+	if err != nil {
+		return err
+	}
+	price, currency := money(b.Price)
+	if _, err := tx.Exec(`INSERT INTO bid_version
+		(bid_id, version, name, description, status, decision, feedback, created_at, updated_by, updated_at,
+		price, price_currency, attachments)
+		SELECT id, version, name, description, status, decision, feedback, created_at, updated_by, updated_at,
+		price, price_currency, attachments
+		FROM bid WHERE id = $1 AND version = $2
+		ON CONFLICT (bid_id, version) DO NOTHING`, b.ID, expected); err != nil {
+		return err
+	}
+	res, err := tx.Exec(`UPDATE bid SET name = $2, description = $3, tender_id = $4,
+		author_type = $5, author_id = $6, status = $7, decision = $8, feedback = $9,
+		version = $10, created_at = $11, updated_by = $12, updated_at = $13, price = $14, price_currency = $15,
+		lot_ids = $16, attachments = $17
+		WHERE id = $1 AND version = $18`,
+		b.ID, b.Name, b.Description, b.TenderID, b.AuthorType, b.AuthorID,
+		b.Status, b.Decision, b.Feedback, b.Version, b.CreatedAt, b.UpdatedBy, b.UpdatedAt, price, currency,
+		pq.Array(b.LotIDs), attachments, expected)
+	if err != nil {
+		return err
+	}
+	return expectOne(tx, res, "bid", b.ID)
+}
+
+func (s *Storage) GetBid(id string) (model.Bid, error) {
+	res, err := s.queryBids(`WHERE id = $1`, id)
+	if err != nil {
+		return model.Bid{}, err
+	}
+	if len(res) == 0 {
+		return model.Bid{}, storage.ErrNotFound
+	}
+	return res[0], nil
+}
+
+func (s *Storage) BidsByTender(tenderID string) ([]model.Bid, error) {
+	return s.queryBids(`WHERE tender_id = $1`, tenderID)
+}
+
+func (s *Storage) BidsByAuthor(authorID string) ([]model.Bid, error) {
+	return s.queryBids(`WHERE author_id = $1`, authorID)
+}
+
+func (s *Storage) queryBids(where string, args ...any) ([]model.Bid, error) {
+	rows, err := s.db.Query(`SELECT `+bidColumns+` FROM bid `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	return s.scanBids(rows)
+}
+
+func (s *Storage) scanBids(rows *sql.Rows) ([]model.Bid, error) {
+	defer rows.Close()
+	var res []model.Bid
+	for rows.Next() {
+		var (
+			b     model.Bid
+			price nullMoney
+			err   error
+		)
+		if err := rows.Scan(&b.ID, &b.Name, &b.Description, &b.TenderID, &b.AuthorType, &b.AuthorID,
+			&b.Status, &b.Decision, &b.Feedback, &b.Version, &b.CreatedAt, &b.UpdatedBy, &b.UpdatedAt,
+			&price.amount, &price.currency, pq.Array(&b.LotIDs), jsonColumn{&b.Attachments}); err != nil {
+			return nil, err
+		}
+		if b.Price, err = price.money(); err != nil {
+			return nil, err
+		}
+		res = append(res, b)
+	}
+	return res, rows.Err()
+}
+
+func (s *Storage) BidVersions(id string) ([]model.BidVersion, error) {
+	rows, err := s.db.Query(`SELECT version, name, description, status, decision, feedback, created_at,
+		updated_by, updated_at, price, price_currency, attachments
+		FROM bid_version WHERE bid_id = $1 ORDER BY version`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []model.BidVersion
+	for rows.Next() {
+		var (
+			v     model.BidVersion
+			price nullMoney
+			err   error
+		)
+		if err := rows.Scan(&v.Version, &v.Name, &v.Description, &v.Status, &v.Decision, &v.Feedback, &v.CreatedAt,
+			&v.UpdatedBy, &v.UpdatedAt, &price.amount, &price.currency, jsonColumn{&v.Attachments}); err != nil {
+			return nil, err
+		}
+		if v.Price, err = price.money(); err != nil {
+			return nil, err
+		}
+		res = append(res, v)
+	}
+	return res, rows.Err()
+}
+
+func (s *Storage) AddReview(r model.BidReview, b model.Bid, expected int, e model.AuditEvent) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		if err := updateBid(tx, b, expected); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`INSERT INTO bid_review
+			(id, bid_id, tender_id, author_id, reviewer_username, description, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			r.ID, r.BidID, r.TenderID, r.AuthorID, r.ReviewerUsername, r.Description, r.CreatedAt)
+		if err != nil {
+			return translate(err)
+		}
+		return appendAudit(tx, e)
+	})
+}
+
+func (s *Storage) ReviewsByAuthor(authorID string) ([]model.BidReview, error) {
+	rows, err := s.db.Query(`SELECT id, bid_id, tender_id, author_id, reviewer_username, description, created_at
+		FROM bid_review WHERE author_id = $1
+		ORDER BY created_at`, authorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []model.BidReview
+	for rows.Next() {
+		var r model.BidReview
+		if err := rows.Scan(&r.ID, &r.BidID, &r.TenderID, &r.AuthorID, &r.ReviewerUsername, &r.Description, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, r)
+	}
+	return res, rows.Err()
+}
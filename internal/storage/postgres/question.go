@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"database/sql"
+
+	"tender/internal/model"
+	"tender/internal/storage"
+)
+
+const questionColumns = `id, tender_id, text, asked_by, asked_at, answer, answered_by, answered_at, published,
+	deadline_extended, version`
+
+func (s *Storage) AddQuestion(q model.Question, e model.AuditEvent) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`INSERT INTO tender_question (`+questionColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+			q.ID, q.TenderID, q.Text, q.AskedBy, q.AskedAt, q.Answer, q.AnsweredBy, q.AnsweredAt, q.Published,
+			q.DeadlineExtended, q.Version)
+		if err != nil {
+			return translate(err)
+		}
+		return appendAudit(tx, e)
+	})
+}
+
+func (s *Storage) UpdateQuestion(q model.Question, expected int, e model.AuditEvent) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		res, err := tx.Exec(`UPDATE tender_question
+			SET text = $2, answer = $3, answered_by = $4, answered_at = $5, published = $6,
+			deadline_extended = $7, version = $8
+			WHERE id = $1 AND version = $9`,
+			q.ID, q.Text, q.Answer, q.AnsweredBy, q.AnsweredAt, q.Published, q.DeadlineExtended, q.Version,
+			expected)
+		if err != nil {
+			return err
+		}
+		if err := expectOne(tx, res, "tender_question", q.ID); err != nil {
+			return err
+		}
+		return appendAudit(tx, e)
+	})
+}
+
+func (s *Storage) GetQuestion(id string) (model.Question, error) {
+	res, err := s.queryQuestions(`WHERE id = $1`, id)
+	if err != nil {
+		return model.Question{}, err
+	}
+	if len(res) == 0 {
+		return model.Question{}, storage.ErrNotFound
+	}
+	return res[0], nil
+}
+
+func (s *Storage) QuestionsByTender(tenderID string) ([]model.Question, error) {
+	return s.queryQuestions(`WHERE tender_id = $1 ORDER BY asked_at, id`, tenderID)
+}
+
+func (s *Storage) queryQuestions(where string, args ...any) ([]model.Question, error) {
+	rows, err := s.db.Query(`SELECT `+questionColumns+` FROM tender_question `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []model.Question
+	for rows.Next() {
+		var q model.Question
+		if err := rows.Scan(&q.ID, &q.TenderID, &q.Text, &q.AskedBy, &q.AskedAt, &q.Answer, &q.AnsweredBy,
+			&q.AnsweredAt, &q.Published, &q.DeadlineExtended, &q.Version); err != nil {
+			return nil, err
+		}
+		res = append(res, q)
+	}
+	return res, rows.Err()
+}
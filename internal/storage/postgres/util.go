@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"tender/internal/model"
+	"tender/internal/storage"
+)
+
+// expectOne checks the outcome of a compare-and-swap UPDATE on table. When
+// no row matched it reports storage.ErrConflict if the row exists with a
+// different version and storage.ErrNotFound otherwise.
+func expectOne(tx *sql.Tx, res sql.Result, table, id string) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return nil
+	}
+	var exists bool
+	if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM `+table+` WHERE id = $1)`, id).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return storage.ErrConflict
+	}
+	return storage.ErrNotFound
+}
+
+// translate maps driver errors onto the storage sentinels.
+func translate(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return storage.ErrAlreadyExists
+	}
+	return err
+}
+
+// money passes optional money as a NUMERIC amount and a CHAR(3) currency
+// parameter.
+func money(m *model.Money) (amount, currency any) {
+	if m == nil {
+		return nil, nil
+	}
+	return m.Amount.String(), m.Currency
+}
+
+// nullMoney scans optional money stored in an amount and a currency column.
+type nullMoney struct{ amount, currency sql.NullString }
+
+func (n *nullMoney) money() (*model.Money, error) {
+	if !n.amount.Valid {
+		return nil, nil
+	}
+	// NUMERIC(19,4) always comes back with four fractional digits.
+	a, err := model.ParseAmount(n.amount.String)
+	if err != nil {
+		return nil, fmt.Errorf("scan amount %q: %w", n.amount.String, err)
+	}
+	return &model.Money{Amount: a, Currency: n.currency.String}, nil
+}
+
+// jsonb passes v as a JSONB parameter, or NULL when v is a nil pointer.
+func jsonb[T any](v *T) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+// jsonColumn scans a JSONB column into the value dst points to, leaving it
+// as it is when the column is NULL.
+type jsonColumn struct{ dst any }
+
+func (j jsonColumn) Scan(src any) error {
+	if src == nil {
+		return nil
+	}
+	b, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("scan json: unexpected %T", src)
+	}
+	return json.Unmarshal(b, j.dst)
+}
+
+// nullJSON scans an optional JSONB column into *dst.
+type nullJSON[T any] struct{ dst **T }
+
+func (n nullJSON[T]) Scan(src any) error {
+	*n.dst = nil
+	if src == nil {
+		return nil
+	}
+	b, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("scan json: unexpected %T", src)
+	}
+	v := new(T)
+	if err := json.Unmarshal(b, v); err != nil {
+		return err
+	}
+	*n.dst = v
+	return nil
+}
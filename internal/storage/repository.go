@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"errors"
+	"time"
+
+	"tender/internal/model"
+)
+
+var (
+	// ErrNotFound is returned by repositories when the requested entity does
+	// not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrAlreadyExists is returned when an insert violates a uniqueness rule.
+	ErrAlreadyExists = errors.New("already exists")
+	// ErrConflict is returned by compare-and-swap updates when the stored
+	// version no longer matches the expected one.
+	ErrConflict = errors.New("version conflict")
+)
+
+// TenderRepository stores the current state of tenders. History on a
+// stored tender is ignored.
+type TenderRepository interface {
+	// AddTender and UpdateTender append e to the audit log (see
+	// AuditRepository) in the same step as the change it records.
+	AddTender(t model.Tender, e model.AuditEvent) error
+	// UpdateTender replaces the stored tender only if its version is still
+	// expected, and returns ErrConflict otherwise. The replaced state is
+	// archived as a version in the same step.
+	UpdateTender(t model.Tender, expected int, e model.AuditEvent) error
+	GetTender(id string) (model.Tender, error)
+	// ListTenders returns the tenders matching f.
+	ListTenders(f TenderFilter) ([]model.Tender, error)
+	TendersByCreator(username string) ([]model.Tender, error)
+}
+
+// TenderFilter selects tenders for ListTenders. A tender matches when it
+// satisfies every field that is set; a list field is satisfied by any of
+// its values. The zero filter matches every tender.
+type TenderFilter struct {
+	ServiceTypes    []string
+	Statuses        []string
+	OrganizationIDs []string
+	CreatorUsername string
+	// ExpiredBy matches tenders whose closing deadline (see
+	// model.Tender.ClosingDeadline) is at or before it, with DecisionWindow
+	// as the time left for a decision after the submission deadline.
+	ExpiredBy      time.Time
+	DecisionWindow time.Duration
+}
+
+// BidRepository stores the current state of bids. History on a stored bid
+// is ignored.
+type BidRepository interface {
+	// AddBid and UpdateBid append e to the audit log in the same step as
+	// the change it records.
+	AddBid(b model.Bid, e model.AuditEvent) error
+	// UpdateBid replaces the stored bid only if its version is still
+	// expected, and returns ErrConflict otherwise. The replaced state is
+	// archived as a version in the same step.
+	UpdateBid(b model.Bid, expected int, e model.AuditEvent) error
+	GetBid(id string) (model.Bid, error)
+	BidsByTender(tenderID string) ([]model.Bid, error)
+	BidsByAuthor(authorID string) ([]model.Bid, error)
+}
+
+// VersionRepository reads and prunes the past versions archived by
+// UpdateTender and UpdateBid.
+type VersionRepository interface {
+	// TenderVersions and BidVersions return the archived versions of an
+	// entity, oldest first. The current state is not among them.
+	TenderVersions(tenderID string) ([]model.TenderVersion, error)
+	BidVersions(bidID string) ([]model.BidVersion, error)
+	// PruneVersions deletes, for every entity, archived versions beyond the
+	// keepLast most recent and those produced before cutoff. A zero keepLast
+	// or cutoff disables that limit. It returns how many were deleted.
+	PruneVersions(keepLast int, cutoff time.Time) (int, error)
+}
+
+// DecisionRepository stores per-responsible votes on bids. A user can vote
+// on a bid only once; AddDecision returns ErrAlreadyExists otherwise. It
+// appends e to the audit log in the same step as the vote.
+type DecisionRepository interface {
+	AddDecision(d model.BidDecision, e model.AuditEvent) error
+	ListDecisions(bidID string) ([]model.BidDecision, error)
+}
+
+// EvaluationRepository stores the scores evaluators gave bids. There is
+// one evaluation per bid and evaluator: PutEvaluation replaces the scores
+// of an earlier one, keeping its ID and creation time, and appends event to
+// the audit log in the same step.
+type EvaluationRepository interface {
+	PutEvaluation(e model.BidEvaluation, event model.AuditEvent) error
+	EvaluationsByTender(tenderID string) ([]model.BidEvaluation, error)
+}
+
+// QuestionRepository stores the clarifications asked on tenders. Its
+// writes append e to the audit log in the same step as the change.
+type QuestionRepository interface {
+	AddQuestion(q model.Question, e model.AuditEvent) error
+	// UpdateQuestion replaces the stored question only if its version is
+	// still expected, and returns ErrConflict otherwise.
+	UpdateQuestion(q model.Question, expected int, e model.AuditEvent) error
+	GetQuestion(id string) (model.Question, error)
+	// QuestionsByTender returns the questions on tenderID, oldest first.
+	QuestionsByTender(tenderID string) ([]model.Question, error)
+}
+
+// ReviewRepository stores feedback left on bids.
+type ReviewRepository interface {
+	// AddReview adds r in the same step as the update of the bid it was
+	// left on, which it makes as UpdateBid does, appending e.
+	AddReview(r model.BidReview, b model.Bid, expected int, e model.AuditEvent) error
+	// ReviewsByAuthor returns reviews on every bid authored by authorID.
+	ReviewsByAuthor(authorID string) ([]model.BidReview, error)
+}
+
+// AuditRepository reads the append-only audit log. Events are appended by
+// the writes of the other repositories, each together with the change it
+// records: it is chained to the last stored event (see
+// model.AuditEvent.Chain) and stored atomically with the change and with
+// respect to other appends, so that no change goes unrecorded and no event
+// records a change that did not happen.
+type AuditRepository interface {
+	// ListAudit returns the events matching f in log order.
+	ListAudit(f AuditFilter) ([]model.AuditEvent, error)
+}
+
+// AuditFilter selects events for ListAudit. An event matches when it
+// satisfies every field that is set; TenderIDs is satisfied by any of its
+// values. The zero filter matches every event.
+type AuditFilter struct {
+	Actor      string
+	Action     string
+	EntityType string
+	EntityID   string
+	TenderIDs  []string
+	// Since and Until bound the time of the event: at or after Since and
+	// before Until.
+	Since time.Time
+	Until time.Time
+}
+
+// UserRepository is the read-only employee and organization directory.
+type UserRepository interface {
+	GetEmployee(username string) (model.Employee, error)
+	GetEmployeeByID(id string) (model.Employee, error)
+	GetOrganization(id string) (model.Organization, error)
+	IsResponsible(orgID, userID string) (bool, error)
+	OrganizationResponsibles(orgID string) ([]model.Employee, error)
+	// ResponsibleOrganizations returns the IDs of the organizations userID
+	// is responsible for.
+	ResponsibleOrganizations(userID string) ([]string, error)
+}
+
+// Repository is implemented by every storage backend: the in-memory store,
+// the JSON file store in this package and the PostgreSQL store in
+// storage/postgres.
+type Repository interface {
+	TenderRepository
+	BidRepository
+	VersionRepository
+	DecisionRepository
+	EvaluationRepository
+	QuestionRepository
+	ReviewRepository
+	AuditRepository
+	UserRepository
+}
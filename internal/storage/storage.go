@@ -1,131 +1,236 @@
 package storage
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"os"
-	"sync"
+	"path/filepath"
 
 	"tender/internal/model"
 )
 
 type Data struct {
-	Tenders []model.Tender    `json:"tenders"`
-	Bids    []model.Bid       `json:"bids"`
-	Reviews []model.BidReview `json:"reviews"`
+	Tenders     []model.Tender        `json:"tenders"`
+	Bids        []model.Bid           `json:"bids"`
+	Decisions   []model.BidDecision   `json:"decisions"`
+	Evaluations []model.BidEvaluation `json:"evaluations"`
+	Questions   []model.Question      `json:"questions"`
+	Reviews     []model.BidReview     `json:"reviews"`
+	// Audit is the audit log, oldest first.
+	Audit []model.AuditEvent `json:"audit"`
+
+	// TenderVersions and BidVersions hold the archived versions of each
+	// tender and bid by ID, oldest first.
+	TenderVersions map[string][]model.TenderVersion `json:"tenderVersions,omitempty"`
+	BidVersions    map[string][]model.BidVersion    `json:"bidVersions,omitempty"`
+
+	Employees     []model.Employee                `json:"employees"`
+	Organizations []model.Organization            `json:"organizations"`
+	Responsibles  []model.OrganizationResponsible `json:"organizationResponsibles"`
 }
 
+// snapshot is the content of the data file: the dataset as of operation
+// Seq. Files written before the operation log existed have no seq.
+type snapshot struct {
+	Seq uint64 `json:"seq,omitempty"`
+	Data
+}
+
+// DefaultCompactEvery is the number of logged operations after which the
+// log is folded into the data file.
+const DefaultCompactEvery = 1000
+
+// Storage is the JSON file backend: a Memory store whose mutations are
+// appended to an operation log (path + ".wal") and synced before they are
+// applied. The log is periodically compacted into the data file, which is
+// only ever replaced atomically, so a crash at any point loses at most the
+// operation being written.
 type Storage struct {
+	*Memory
 	path string
-	mu   sync.Mutex
-	Data Data
+	log  *os.File
+	seq  uint64
+	// logged counts the operations in the log since the last compaction.
+	logged int
+
+	// CompactEvery is the log length that triggers a compaction.
+	CompactEvery int
 }
 
 func New(path string) (*Storage, error) {
-	s := &Storage{path: path}
+	s := &Storage{path: path, Memory: NewMemory(), CompactEvery: DefaultCompactEvery}
 	if err := s.load(); err != nil {
 		return nil, err
 	}
+	// Start from a fresh snapshot so that the replayed log is not replayed
+	// again on the next start.
+	if err := s.compact(); err != nil {
+		return nil, err
+	}
+	s.persist = s.append
 	return s, nil
 }
 
+func (s *Storage) walPath() string {
+	return s.path + ".wal"
+}
+
+// load reads the data file and replays the operations logged after it. A
+// torn last line, left by a crash in the middle of an append, is dropped.
 func (s *Storage) load() error {
+	var snap snapshot
 	file, err := os.Open(s.path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
+	switch {
+	case err == nil:
+		err = json.NewDecoder(file).Decode(&snap)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("read %s: %w", s.path, err)
 		}
+	case !os.IsNotExist(err):
 		return err
 	}
-	defer file.Close()
-	return json.NewDecoder(file).Decode(&s.Data)
-}
+	s.reset(snap.Data)
+	s.seq = snap.Seq
 
-func (s *Storage) save() error {
-	file, err := os.Create(s.path)
+	wal, err := os.ReadFile(s.walPath())
+	if os.IsNotExist(err) {
+		return nil
+	}
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-	enc := json.NewEncoder(file)
-	enc.SetIndent("", "  ")
-	return enc.Encode(s.Data)
-}
-
-func (s *Storage) AddTender(t model.Tender) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.Data.Tenders = append(s.Data.Tenders, t)
-	return s.save()
-}
-
-func (s *Storage) UpdateTender(t model.Tender) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	for i := range s.Data.Tenders {
-		if s.Data.Tenders[i].ID == t.ID {
-			s.Data.Tenders[i] = t
-			return s.save()
+	for line := 1; len(wal) > 0; line++ {
+		entry, rest, complete := bytes.Cut(wal, []byte("\n"))
+		wal = rest
+		var o op
+		if err := json.Unmarshal(entry, &o); err != nil {
+			if !complete {
+				break
+			}
+			return fmt.Errorf("replay %s line %d: %w", s.walPath(), line, err)
+		}
+		// Operations up to the snapshot's seq are already in it: the process
+		// stopped between writing the snapshot and truncating the log.
+		if o.Seq <= s.seq {
+			continue
 		}
+		s.apply(o)
+		s.seq = o.Seq
 	}
-	return os.ErrNotExist
+	return nil
 }
 
-func (s *Storage) GetTender(id string) (model.Tender, bool) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	for _, t := range s.Data.Tenders {
-		if t.ID == id {
-			return t, true
+// append writes o to the log and syncs it. It runs under the Memory lock,
+// with every earlier operation already applied, which is when a due
+// compaction happens.
+func (s *Storage) append(o op) error {
+	if s.logged >= s.CompactEvery {
+		if err := s.compact(); err != nil {
+			return err
 		}
 	}
-	return model.Tender{}, false
+	o.Seq = s.seq + 1
+	line, err := json.Marshal(o)
+	if err != nil {
+		return err
+	}
+	info, err := s.log.Stat()
+	if err != nil {
+		return err
+	}
+	_, err = s.log.Write(append(line, '\n'))
+	if err == nil {
+		err = s.log.Sync()
+	}
+	if err != nil {
+		// Cut off a partial line so that later operations are not logged
+		// after garbage.
+		return errors.Join(err, s.log.Truncate(info.Size()))
+	}
+	s.seq = o.Seq
+	s.logged++
+	return nil
 }
 
-func (s *Storage) AddBid(b model.Bid) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.Data.Bids = append(s.Data.Bids, b)
-	return s.save()
+// compact writes the current data to the data file and starts a new log.
+func (s *Storage) compact() error {
+	return s.writeSnapshot(snapshot{Seq: s.seq, Data: s.data})
 }
 
-func (s *Storage) UpdateBid(b model.Bid) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	for i := range s.Data.Bids {
-		if s.Data.Bids[i].ID == b.ID {
-			s.Data.Bids[i] = b
-			return s.save()
-		}
+// writeSnapshot atomically replaces the data file with snap and truncates
+// the log. Until the truncation the log still holds operations that are
+// already in the snapshot; load skips them by seq.
+func (s *Storage) writeSnapshot(snap snapshot) error {
+	if err := writeFileAtomic(s.path, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(snap)
+	}); err != nil {
+		return err
 	}
-	return os.ErrNotExist
-}
-
-func (s *Storage) GetBid(id string) (model.Bid, bool) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	for _, b := range s.Data.Bids {
-		if b.ID == id {
-			return b, true
-		}
+	if s.log != nil {
+		s.log.Close()
+	}
+	log, err := os.OpenFile(s.walPath(), os.O_CREATE|os.O_TRUNC|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
 	}
-	return model.Bid{}, false
+	s.log = log
+	s.logged = 0
+	return nil
 }
 
-func (s *Storage) AddReview(r model.BidReview) error {
+// Close compacts the log into the data file and releases it.
+func (s *Storage) Close() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.Data.Reviews = append(s.Data.Reviews, r)
-	return s.save()
+	err := s.compact()
+	return errors.Join(err, s.log.Close())
 }
 
-func (s *Storage) ListReviews(tenderID, author string) []model.BidReview {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	var res []model.BidReview
-	for _, r := range s.Data.Reviews {
-		if r.TenderID == tenderID && r.AuthorUsername == author {
-			res = append(res, r)
-		}
+// writeFileAtomic writes path through a synced temporary file in the same
+// directory that is renamed over it, so readers and crashes only ever see
+// the old or the new content.
+func writeFileAtomic(path string, write func(io.Writer) error) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	w := bufio.NewWriter(tmp)
+	if err := write(w); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	// Sync the directory so the rename itself survives a crash.
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
 	}
-	return res
+	defer d.Close()
+	return d.Sync()
 }
@@ -0,0 +1,242 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"tender/internal/model"
+)
+
+// open opens a file store at a fresh path.
+func open(t *testing.T) *Storage {
+	t.Helper()
+	s, err := New(filepath.Join(t.TempDir(), "data.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// crash copies the data file and log of s as a crash would leave them,
+// with wal as the log when set, and opens the copy.
+func crash(t *testing.T, s *Storage, wal []byte) (*Storage, error) {
+	t.Helper()
+	if wal == nil {
+		wal = readFile(t, s.walPath())
+	}
+	path := filepath.Join(t.TempDir(), "data.json")
+	writeFile(t, path, readFile(t, s.path))
+	writeFile(t, path+".wal", wal)
+	c, err := New(path)
+	if err == nil {
+		t.Cleanup(func() { c.Close() })
+	}
+	return c, err
+}
+
+func readFile(t *testing.T, path string) []byte {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func writeFile(t *testing.T, path string, b []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// tender adds a tender named name to s.
+func tender(t *testing.T, s *Storage, id, name string) model.Tender {
+	t.Helper()
+	tn := model.Tender{ID: id, Name: name, Status: model.TenderCreated, Version: 1, CreatorUsername: "alice"}
+	if err := s.AddTender(tn, model.AuditEvent{Action: model.AuditTenderCreate, EntityID: id, TenderID: id}); err != nil {
+		t.Fatal(err)
+	}
+	return tn
+}
+
+// rename stores tender id of s under a new name as its next version.
+func rename(t *testing.T, s *Storage, id, name string) model.Tender {
+	t.Helper()
+	tn, err := s.GetTender(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tn.Name = name
+	tn.Version++
+	if err := s.UpdateTender(tn, tn.Version-1, model.AuditEvent{Action: model.AuditTenderEdit, EntityID: id, TenderID: id}); err != nil {
+		t.Fatal(err)
+	}
+	return tn
+}
+
+// wantSame fails the test unless a and b hold the same data.
+func wantSame(t *testing.T, a, b *Storage) {
+	t.Helper()
+	if !reflect.DeepEqual(normalize(t, a.data), normalize(t, b.data)) {
+		t.Fatalf("data differ:\n%+v\n%+v", a.data, b.data)
+	}
+}
+
+// normalize returns d as the data file stores it.
+func normalize(t *testing.T, d Data) Data {
+	t.Helper()
+	b, err := json.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res Data
+	if err := json.Unmarshal(b, &res); err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestReplayLog(t *testing.T) {
+	s := open(t)
+	tender(t, s, "t1", "a")
+	rename(t, s, "t1", "b")
+	tender(t, s, "t2", "c")
+
+	c, err := crash(t, s, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantSame(t, s, c)
+	if c.seq != 3 {
+		t.Fatalf("seq %d after replay, want 3", c.seq)
+	}
+	// Writes after the replay continue the sequence.
+	rename(t, c, "t2", "d")
+	if c.seq != 4 {
+		t.Fatalf("seq %d, want 4", c.seq)
+	}
+}
+
+func TestReplaySkipsOperationsInSnapshot(t *testing.T) {
+	s := open(t)
+	tender(t, s, "t1", "a")
+	rename(t, s, "t1", "b")
+	stale := readFile(t, s.walPath())
+	s.mu.Lock()
+	if err := s.compact(); err != nil {
+		t.Fatal(err)
+	}
+	s.mu.Unlock()
+	rename(t, s, "t1", "c")
+
+	// The process stopped after writing the snapshot but before cutting
+	// the log: the log still starts with operations 1 and 2, which would
+	// add t1 twice if replayed.
+	c, err := crash(t, s, append(stale, readFile(t, s.walPath())...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantSame(t, s, c)
+	if len(c.data.Tenders) != 1 || c.data.Tenders[0].Name != "c" || len(c.data.Audit) != 3 {
+		t.Fatalf("tenders %+v with %d events, want t1 named c with 3", c.data.Tenders, len(c.data.Audit))
+	}
+}
+
+func TestCompactThenReplay(t *testing.T) {
+	s := open(t)
+	s.CompactEvery = 2
+	tender(t, s, "t1", "a")
+	for _, name := range []string{"b", "c", "d", "e"} {
+		rename(t, s, "t1", name)
+	}
+
+	// A write finding two operations logged compacts first: the third and
+	// the fifth did, so the data file holds four writes and the log one.
+	var snap snapshot
+	if err := json.Unmarshal(readFile(t, s.path), &snap); err != nil {
+		t.Fatal(err)
+	}
+	if snap.Seq != 4 || snap.Tenders[0].Name != "d" {
+		t.Fatalf("snapshot at seq %d with %+v, want seq 4 with d", snap.Seq, snap.Tenders)
+	}
+	if lines := bytes.Count(readFile(t, s.walPath()), []byte("\n")); lines != 1 {
+		t.Fatalf("log holds %d operations, want 1", lines)
+	}
+
+	c, err := crash(t, s, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantSame(t, s, c)
+	if got := c.data.Tenders[0]; got.Name != "e" || got.Version != 5 || len(c.data.TenderVersions["t1"]) != 4 {
+		t.Fatalf("tender %q at version %d with %d archived, want e at 5 with 4",
+			got.Name, got.Version, len(c.data.TenderVersions["t1"]))
+	}
+}
+
+func TestReplayTornLine(t *testing.T) {
+	s := open(t)
+	tender(t, s, "t1", "a")
+	rename(t, s, "t1", "b")
+	rename(t, s, "t1", "c")
+	lines := bytes.SplitAfter(readFile(t, s.walPath()), []byte("\n"))
+
+	// A torn last line is the operation being written at the crash: it is
+	// dropped with its audit event.
+	torn := bytes.Join(lines[:2], nil)
+	torn = append(torn, lines[2][:len(lines[2])/2]...)
+	c, err := crash(t, s, torn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.data.Tenders[0]; got.Name != "b" || len(c.data.Audit) != 2 {
+		t.Fatalf("tender named %q with %d events, want b with 2", got.Name, len(c.data.Audit))
+	}
+
+	// A damaged line before the end is not a crash but a corrupt log, and
+	// replaying past it would lose the operation.
+	corrupt := bytes.Join([][]byte{lines[0], lines[1][:len(lines[1])/2], []byte("\n"), lines[2]}, nil)
+	_, err = crash(t, s, corrupt)
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("err = %v, want a replay error on line 2", err)
+	}
+}
+
+func TestReplayLegacyOperations(t *testing.T) {
+	s := open(t)
+	tender(t, s, "t1", "a")
+	// Logs written before events and reviews came with the change they
+	// record hold them as operations of their own.
+	var wal []byte
+	for i, o := range []op{
+		{Kind: opAppendAudit, Audit: &model.AuditEvent{Seq: 2, Action: model.AuditBidFeedback, TenderID: "t1"}},
+		{Kind: opAddReview, Review: &model.BidReview{ID: "r1", AuthorID: "u1"}},
+	} {
+		o.Seq = s.seq + uint64(i) + 1
+		line, err := json.Marshal(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wal = append(wal, append(line, '\n')...)
+	}
+	c, err := crash(t, s, append(readFile(t, s.walPath()), wal...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.data.Audit) != 2 || c.data.Audit[1].Action != model.AuditBidFeedback {
+		t.Fatalf("audit %+v, want the legacy event last", c.data.Audit)
+	}
+	if reviews, _ := c.ReviewsByAuthor("u1"); len(reviews) != 1 {
+		t.Fatalf("got %d reviews by u1, want 1", len(reviews))
+	}
+	if events, _ := c.ListAudit(AuditFilter{TenderIDs: []string{"t1"}}); len(events) != 2 {
+		t.Fatalf("got %d events of t1, want 2", len(events))
+	}
+}
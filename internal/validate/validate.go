@@ -0,0 +1,88 @@
+// Package validate collects field-level input errors so a request can be
+// rejected with every problem reported at once.
+package validate
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// FieldError describes why a single field was rejected.
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// Errors is a non-empty list of field errors.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	parts := make([]string, len(e))
+	for i, f := range e {
+		parts[i] = f.Field + ": " + f.Reason
+	}
+	return "invalid request: " + strings.Join(parts, "; ")
+}
+
+// Validator accumulates field errors. The zero value is ready to use.
+type Validator struct {
+	errs Errors
+}
+
+// Add records a custom error for field.
+func (v *Validator) Add(field, reason string) {
+	v.errs = append(v.errs, FieldError{Field: field, Reason: reason})
+}
+
+// Required rejects an empty value.
+func (v *Validator) Required(field, value string) bool {
+	if value == "" {
+		v.Add(field, "is required")
+		return false
+	}
+	return true
+}
+
+// MaxLen rejects values longer than n characters.
+func (v *Validator) MaxLen(field, value string, n int) {
+	if utf8.RuneCountInString(value) > n {
+		v.Add(field, fmt.Sprintf("must be at most %d characters", n))
+	}
+}
+
+// OneOf rejects values outside allowed.
+func (v *Validator) OneOf(field, value string, allowed ...string) {
+	if !slices.Contains(allowed, value) {
+		v.Add(field, "must be one of "+strings.Join(allowed, ", "))
+	}
+}
+
+// UUID rejects values that are not a canonical UUID.
+func (v *Validator) UUID(field, value string) {
+	if len(value) != 36 {
+		v.Add(field, "must be a UUID")
+		return
+	}
+	if _, err := uuid.Parse(value); err != nil {
+		v.Add(field, "must be a UUID")
+	}
+}
+
+// Positive rejects n < 1.
+func (v *Validator) Positive(field string, n int) {
+	if n < 1 {
+		v.Add(field, "must be a positive integer")
+	}
+}
+
+// Err returns the collected errors, or nil when there are none.
+func (v *Validator) Err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
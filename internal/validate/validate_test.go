@@ -0,0 +1,107 @@
+package validate
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRules(t *testing.T) {
+	cases := []struct {
+		name  string
+		check func(v *Validator)
+		want  Errors
+	}{
+		{"required set", func(v *Validator) { v.Required("name", "x") }, nil},
+		{"required empty", func(v *Validator) { v.Required("name", "") }, Errors{{"name", "is required"}}},
+		{"required space", func(v *Validator) { v.Required("name", " ") }, nil},
+
+		{"max length", func(v *Validator) { v.MaxLen("name", "abc", 3) }, nil},
+		{"max length exceeded", func(v *Validator) { v.MaxLen("name", "abcd", 3) },
+			Errors{{"name", "must be at most 3 characters"}}},
+		// Length counts characters, not bytes.
+		{"max length in runes", func(v *Validator) { v.MaxLen("name", "тендер", 6) }, nil},
+		{"max length in runes exceeded", func(v *Validator) { v.MaxLen("name", "тендеры", 6) },
+			Errors{{"name", "must be at most 6 characters"}}},
+		{"max length empty", func(v *Validator) { v.MaxLen("name", "", 0) }, nil},
+
+		{"one of", func(v *Validator) { v.OneOf("status", "Open", "Open", "Closed") }, nil},
+		{"one of other", func(v *Validator) { v.OneOf("status", "open", "Open", "Closed") },
+			Errors{{"status", "must be one of Open, Closed"}}},
+		{"one of empty", func(v *Validator) { v.OneOf("status", "", "Open", "Closed") },
+			Errors{{"status", "must be one of Open, Closed"}}},
+
+		{"uuid", func(v *Validator) { v.UUID("id", "550e8400-e29b-41d4-a716-446655440000") }, nil},
+		{"uuid upper case", func(v *Validator) { v.UUID("id", "550E8400-E29B-41D4-A716-446655440000") }, nil},
+		{"uuid empty", func(v *Validator) { v.UUID("id", "") }, Errors{{"id", "must be a UUID"}}},
+		{"uuid without hyphens", func(v *Validator) { v.UUID("id", "550e8400e29b41d4a716446655440000") },
+			Errors{{"id", "must be a UUID"}}},
+		{"uuid in braces", func(v *Validator) { v.UUID("id", "{550e8400-e29b-41d4-a716-446655440000}") },
+			Errors{{"id", "must be a UUID"}}},
+		{"uuid urn", func(v *Validator) { v.UUID("id", "urn:uuid:550e8400-e29b-41d4-a716-446655440000") },
+			Errors{{"id", "must be a UUID"}}},
+		{"uuid bad digit", func(v *Validator) { v.UUID("id", "550e8400-e29b-41d4-a716-44665544000g") },
+			Errors{{"id", "must be a UUID"}}},
+		{"uuid misplaced hyphen", func(v *Validator) { v.UUID("id", "550e840-0e29b-41d4-a716-446655440000") },
+			Errors{{"id", "must be a UUID"}}},
+
+		{"positive", func(v *Validator) { v.Positive("version", 1) }, nil},
+		{"positive zero", func(v *Validator) { v.Positive("version", 0) },
+			Errors{{"version", "must be a positive integer"}}},
+		{"positive negative", func(v *Validator) { v.Positive("version", -3) },
+			Errors{{"version", "must be a positive integer"}}},
+
+		{"custom", func(v *Validator) { v.Add("body", "is broken") }, Errors{{"body", "is broken"}}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var v Validator
+			c.check(&v)
+			err := v.Err()
+			if c.want == nil {
+				if err != nil {
+					t.Fatalf("err = %v, want nil", err)
+				}
+				return
+			}
+			var got Errors
+			if !errors.As(err, &got) || !reflect.DeepEqual(got, c.want) {
+				t.Fatalf("err = %#v, want %#v", err, c.want)
+			}
+		})
+	}
+}
+
+func TestRequiredReports(t *testing.T) {
+	var v Validator
+	if !v.Required("name", "x") {
+		t.Error("Required of a set value = false")
+	}
+	if v.Required("name", "") {
+		t.Error("Required of an empty value = true")
+	}
+}
+
+func TestErrorsCollect(t *testing.T) {
+	var v Validator
+	if err := v.Err(); err != nil {
+		t.Fatalf("zero Validator: err = %v", err)
+	}
+	v.Required("name", "")
+	v.MaxLen("description", "abcd", 2)
+	v.Positive("version", 0)
+	want := Errors{
+		{"name", "is required"},
+		{"description", "must be at most 2 characters"},
+		{"version", "must be a positive integer"},
+	}
+	var got Errors
+	if err := v.Err(); !errors.As(err, &got) || !reflect.DeepEqual(got, want) {
+		t.Fatalf("err = %#v, want %#v", err, want)
+	}
+	const msg = "invalid request: name: is required; description: must be at most 2 characters; " +
+		"version: must be a positive integer"
+	if got.Error() != msg {
+		t.Fatalf("message %q, want %q", got.Error(), msg)
+	}
+}
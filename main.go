@@ -3,7 +3,10 @@ package main
 import (
     "log"
     "net/http"
+    "net/url"
     "os"
+    "strconv"
+    "time"
 
     "github.com/go-chi/chi/v5"
     "github.com/go-chi/chi/v5/middleware"
@@ -11,6 +14,8 @@ import (
     "tender/internal/handler"
     "tender/internal/service"
     "tender/internal/storage"
+    "tender/internal/storage/blob"
+    "tender/internal/storage/postgres"
 )
 
 func main() {
@@ -19,26 +24,179 @@ func main() {
         addr = "0.0.0.0:8080"
     }
 
-    repo, err := storage.New("data.json")
+    repo, err := openStorage()
     if err != nil {
         log.Fatalf("storage: %v", err)
     }
 
-    tenderSvc := service.NewTenderService(repo)
-    bidSvc := service.NewBidService(repo)
+    tenderSvc := service.NewTenderService(repo, repo, repo)
+    bidSvc := service.NewBidService(repo, repo, repo, repo, repo, repo, repo)
+    lotSvc := service.NewLotService(repo, repo, repo)
+    questionSvc := service.NewQuestionService(repo, repo, repo)
+    auditSvc := service.NewAuditService(repo, repo, repo)
+
+    blobs, err := openBlobStore()
+    if err != nil {
+        log.Fatalf("blob store: %v", err)
+    }
+    attachmentSvc := service.NewAttachmentService(repo, repo, repo, blobs, repo)
+    if n, err := strconv.ParseInt(os.Getenv("ATTACHMENT_MAX_BYTES"), 10, 64); err == nil && n > 0 {
+        attachmentSvc.MaxSize = n
+    }
+
+    if retention := historyRetention(); retention.Enabled() {
+        go pruneHistory(repo, retention)
+    }
+    scheduler := service.NewScheduler(repo, repo, service.SystemClock)
+    if d, err := time.ParseDuration(os.Getenv("DECISION_WINDOW")); err == nil && d > 0 {
+        scheduler.DecisionWindow = d
+    }
+    go closeExpiredTenders(scheduler, deadlineInterval())
 
     tenderHandler := handler.NewTenderHandler(tenderSvc)
     bidHandler := handler.NewBidHandler(bidSvc)
+    lotHandler := handler.NewLotHandler(lotSvc)
+    questionHandler := handler.NewQuestionHandler(questionSvc)
+    attachmentHandler := handler.NewAttachmentHandler(attachmentSvc)
+    auditHandler := handler.NewAuditHandler(auditSvc)
 
     r := chi.NewRouter()
+    r.Use(handler.RequestID)
     r.Use(middleware.Logger)
+    r.NotFound(handler.NotFound)
+    r.MethodNotAllowed(handler.MethodNotAllowed)
 
     r.Get("/api/ping", handler.Ping)
     r.Mount("/api/tenders", tenderHandler.Routes())
+    r.Mount("/api/tenders/{id}/lots", lotHandler.Routes())
+    r.Mount("/api/tenders/{id}/questions", questionHandler.Routes())
+    r.Mount("/api/tenders/{id}/attachments", attachmentHandler.TenderRoutes())
     r.Mount("/api/bids", bidHandler.Routes())
+    r.Mount("/api/bids/{id}/attachments", attachmentHandler.BidRoutes())
+    r.Mount("/api/audit", auditHandler.Routes())
 
     log.Printf("Starting server on %s", addr)
     if err := http.ListenAndServe(addr, r); err != nil {
         log.Fatalf("server failed: %v", err)
     }
 }
+
+// openStorage picks the PostgreSQL backend when it is configured through the
+// POSTGRES_* environment and falls back to the local data.json file otherwise.
+func openStorage() (storage.Repository, error) {
+    dsn := postgresDSN()
+    if dsn == "" {
+        log.Printf("POSTGRES_CONN is not set, using data.json")
+        return storage.New("data.json")
+    }
+    log.Printf("Using PostgreSQL storage")
+    return postgres.New(dsn)
+}
+
+// openBlobStore picks the S3-compatible store when S3_BUCKET is set, at
+// S3_ENDPOINT with S3_REGION, S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY, and
+// falls back to the BLOB_DIR directory, "blobs" by default, otherwise.
+func openBlobStore() (blob.Store, error) {
+    bucket := os.Getenv("S3_BUCKET")
+    if bucket == "" {
+        dir := os.Getenv("BLOB_DIR")
+        if dir == "" {
+            dir = "blobs"
+        }
+        log.Printf("S3_BUCKET is not set, keeping attachments in %s", dir)
+        return blob.NewFS(dir)
+    }
+    log.Printf("Keeping attachments in S3 bucket %s", bucket)
+    return blob.NewS3(blob.S3Config{
+        Endpoint:        os.Getenv("S3_ENDPOINT"),
+        Bucket:          bucket,
+        Region:          os.Getenv("S3_REGION"),
+        AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+        SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+    })
+}
+
+// postgresDSN builds a connection URL from POSTGRES_CONN, or from the
+// individual POSTGRES_HOST/PORT/USERNAME/PASSWORD/DATABASE variables.
+func postgresDSN() string {
+    dsn := os.Getenv("POSTGRES_CONN")
+    if dsn == "" {
+        host := os.Getenv("POSTGRES_HOST")
+        if host == "" {
+            return ""
+        }
+        if port := os.Getenv("POSTGRES_PORT"); port != "" {
+            host += ":" + port
+        }
+        u := url.URL{
+            Scheme: "postgres",
+            User:   url.UserPassword(os.Getenv("POSTGRES_USERNAME"), os.Getenv("POSTGRES_PASSWORD")),
+            Host:   host,
+            Path:   "/" + os.Getenv("POSTGRES_DATABASE"),
+        }
+        dsn = u.String()
+    }
+    u, err := url.Parse(dsn)
+    if err != nil || u.Scheme == "" {
+        return dsn
+    }
+    // lib/pq defaults to sslmode=require, which local and CI databases rarely support.
+    q := u.Query()
+    if q.Get("sslmode") == "" {
+        q.Set("sslmode", "disable")
+        u.RawQuery = q.Encode()
+    }
+    return u.String()
+}
+
+// historyRetention reads the version retention policy: HISTORY_KEEP_VERSIONS
+// archived versions per tender or bid, and HISTORY_MAX_AGE_DAYS. Unset or
+// invalid values keep everything.
+func historyRetention() service.Retention {
+    var r service.Retention
+    if n, err := strconv.Atoi(os.Getenv("HISTORY_KEEP_VERSIONS")); err == nil && n > 0 {
+        r.KeepLast = n
+    }
+    if days, err := strconv.Atoi(os.Getenv("HISTORY_MAX_AGE_DAYS")); err == nil && days > 0 {
+        r.MaxAge = time.Duration(days) * 24 * time.Hour
+    }
+    return r
+}
+
+// pruneHistory applies the retention policy at startup and then hourly.
+func pruneHistory(repo storage.VersionRepository, r service.Retention) {
+    for {
+        n, err := r.Prune(repo, time.Now())
+        if err != nil {
+            log.Printf("prune history: %v", err)
+        } else if n > 0 {
+            log.Printf("pruned %d archived versions", n)
+        }
+        time.Sleep(time.Hour)
+    }
+}
+
+// deadlineInterval reads how often tenders are checked for a passed
+// closing deadline from DEADLINE_CHECK_INTERVAL (a Go duration such as
+// "30s"). It defaults to a minute.
+func deadlineInterval() time.Duration {
+    if d, err := time.ParseDuration(os.Getenv("DEADLINE_CHECK_INTERVAL")); err == nil && d > 0 {
+        return d
+    }
+    return time.Minute
+}
+
+// closeExpiredTenders closes tenders past their closing deadline at
+// startup and then every interval.
+func closeExpiredTenders(s *service.Scheduler, every time.Duration) {
+    for {
+        n, err := s.CloseExpired()
+        if err != nil {
+            log.Printf("close expired tenders: %v", err)
+        }
+        if n > 0 {
+            log.Printf("closed %d tenders past their deadline", n)
+        }
+        time.Sleep(every)
+    }
+}